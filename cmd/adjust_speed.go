@@ -1,30 +1,91 @@
 package cmd
 
 import (
+	"encoding/json"
 	"log"
+	"os"
+	"runtime"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
 	"github.com/viniciusrtf/sync-audio-with-timestamps/pkg/core"
 )
 
-var manifestPath string
+var (
+	manifestPath       string
+	adjustSpeedBackend string
+	adjustSpeedJobs    int
+	adjustSpeedTimeout time.Duration
+	adjustSpeedOptions manifest.Options
+	adjustSpeedDryRun  bool
+	adjustSpeedFormat  string
+	adjustSpeedReport  string
+)
 
 var adjustSpeedCmd = &cobra.Command{
 	Use:   "adjust-speed",
 	Short: "Adjusts the speed of audio files based on a manifest.",
 	Long: `This command reads a manifest file containing timestamps and audio file paths.
+SubRip (.srt) subtitles are accepted as manifests too.
 It calculates the necessary speed adjustment for each audio file to match the
-target duration and applies it using ffmpeg.`,
+target duration and applies it using ffmpeg, or in pure Go with --backend native.
+
+With --dry-run, every clip is probed and the planned speed factors are
+printed as a table, or as JSON with --format json, without writing anything.`,
 	Run: func(cmd *cobra.Command, args []string) {
 		if manifestPath == "" {
 			log.Fatal("manifest path is required")
 		}
+		if adjustSpeedFormat != "text" && adjustSpeedFormat != "json" {
+			log.Fatalf("Unknown format %q (expected text or json)", adjustSpeedFormat)
+		}
+		if adjustSpeedFormat == "json" && !adjustSpeedDryRun {
+			log.Fatal("--format json needs --dry-run")
+		}
+		if adjustSpeedReport != "" && adjustSpeedDryRun {
+			log.Fatal("--report can't be used with --dry-run, which writes nothing")
+		}
 
-		audioProcessor := audio.NewFFmpegProcessor()
-		coreProcessor := core.NewProcessor(audioProcessor)
+		audioProcessor, err := newAudioProcessor(adjustSpeedBackend)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts := []core.Option{
+			core.WithJobs(adjustSpeedJobs),
+			core.WithTimeout(adjustSpeedTimeout),
+			core.WithManifestOptions(adjustSpeedOptions),
+		}
+		if adjustSpeedFormat == "json" {
+			// Keep standard output for the JSON plan alone.
+			opts = append(opts, core.WithOutput(os.Stderr))
+		}
+		coreProcessor := core.NewProcessor(audioProcessor, opts...)
 
-		if err := coreProcessor.ProcessManifest(manifestPath); err != nil {
+		if adjustSpeedDryRun {
+			plan, err := coreProcessor.PlanSpeed(cmd.Context(), manifestPath)
+			if err != nil {
+				log.Fatalf("Error: %v", err)
+			}
+			if adjustSpeedFormat == "json" {
+				enc := json.NewEncoder(os.Stdout)
+				enc.SetIndent("", "  ")
+				if err := enc.Encode(plan); err != nil {
+					log.Fatal(err)
+				}
+			} else {
+				plan.WriteText(os.Stdout)
+			}
+			return
+		}
+
+		report, err := coreProcessor.ProcessManifestReport(cmd.Context(), manifestPath)
+		if adjustSpeedReport != "" {
+			if reportErr := writeReport(adjustSpeedReport, report); reportErr != nil {
+				log.Printf("Error: %v", reportErr)
+			}
+		}
+		if err != nil {
 			// The core processor logs errors for individual entries, so we only need to handle fatal errors.
 			// A fatal error can be an invalid manifest or a failure to write the new synced manifest.
 			log.Fatalf("Error: %v", err)
@@ -35,5 +96,12 @@ target duration and applies it using ffmpeg.`,
 func init() {
 	rootCmd.AddCommand(adjustSpeedCmd)
 	adjustSpeedCmd.Flags().StringVarP(&manifestPath, "manifest", "m", "", "Path to the manifest file (required)")
+	adjustSpeedCmd.Flags().StringVar(&adjustSpeedBackend, "backend", "ffmpeg", "Audio backend to use: ffmpeg or native (pure Go, WAV only)")
+	adjustSpeedCmd.Flags().IntVarP(&adjustSpeedJobs, "jobs", "j", runtime.NumCPU(), "Number of clips to process in parallel")
+	adjustSpeedCmd.Flags().DurationVar(&adjustSpeedTimeout, "timeout", 0, "Maximum time for each audio operation, e.g. 2m (0 means no limit)")
+	adjustSpeedCmd.Flags().BoolVar(&adjustSpeedDryRun, "dry-run", false, "Probe the clips and print the planned speed factors without writing anything")
+	adjustSpeedCmd.Flags().StringVar(&adjustSpeedFormat, "format", "text", "Dry-run output format: text or json")
+	adjustSpeedCmd.Flags().StringVar(&adjustSpeedReport, "report", "", "Write a JSON report of the run to this path")
+	addManifestFlags(adjustSpeedCmd, &adjustSpeedOptions)
 	adjustSpeedCmd.MarkFlagRequired("manifest")
 }
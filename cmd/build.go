@@ -2,15 +2,21 @@ package cmd
 
 import (
 	"log"
+	"time"
 
 	"github.com/spf13/cobra"
-	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
 	"github.com/viniciusrtf/sync-audio-with-timestamps/pkg/core"
 )
 
 var (
 	buildManifestPath string
 	buildOutputPath   string
+	buildBackend      string
+	buildTimeout      time.Duration
+	buildOptions      manifest.Options
+	buildReport       string
+	buildOverflow     string
 )
 
 var buildCmd = &cobra.Command{
@@ -18,12 +24,32 @@ var buildCmd = &cobra.Command{
 	Short: "Builds a single audio file from a manifest.",
 	Long: `This command takes a manifest of audio clips and concatenates them into a
 single audio file. It inserts silence between clips as needed to ensure they
-start at the correct timestamps specified in the manifest.`,
+start at the correct timestamps specified in the manifest.
+
+--overflow chooses what happens when a clip is still playing at the start of
+the next one: push (default), borrow-gap, overlap, truncate or fail.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		audioProcessor := audio.NewFFmpegProcessor()
-		coreProcessor := core.NewProcessor(audioProcessor)
+		overflow, err := core.ParseOverflowPolicy(buildOverflow)
+		if err != nil {
+			log.Fatal(err)
+		}
+		audioProcessor, err := newAudioProcessor(buildBackend)
+		if err != nil {
+			log.Fatal(err)
+		}
+		coreProcessor := core.NewProcessor(audioProcessor,
+			core.WithTimeout(buildTimeout),
+			core.WithManifestOptions(buildOptions),
+			core.WithOverflow(overflow),
+		)
 
-		if err := coreProcessor.BuildFromManifest(buildManifestPath, buildOutputPath); err != nil {
+		report, err := coreProcessor.BuildFromManifestReport(cmd.Context(), buildManifestPath, buildOutputPath)
+		if buildReport != "" {
+			if reportErr := writeReport(buildReport, report); reportErr != nil {
+				log.Printf("Error: %v", reportErr)
+			}
+		}
+		if err != nil {
 			log.Fatalf("Error during build process: %v", err)
 		}
 		log.Println("Build completed successfully.")
@@ -34,6 +60,11 @@ func init() {
 	rootCmd.AddCommand(buildCmd)
 	buildCmd.Flags().StringVarP(&buildManifestPath, "manifest", "m", "", "Path to the manifest file (required)")
 	buildCmd.Flags().StringVarP(&buildOutputPath, "output", "o", "", "Path for the final output audio file (required)")
+	buildCmd.Flags().StringVar(&buildBackend, "backend", "ffmpeg", "Audio backend to use: ffmpeg or native (pure Go, WAV only)")
+	buildCmd.Flags().DurationVar(&buildTimeout, "timeout", 0, "Maximum time for each audio operation, e.g. 10m (0 means no limit)")
+	buildCmd.Flags().StringVar(&buildOverflow, "overflow", string(core.OverflowPush), "What to do when a clip overruns into the next: push, borrow-gap, overlap, truncate or fail")
+	buildCmd.Flags().StringVar(&buildReport, "report", "", "Write a JSON report of the build to this path")
+	addManifestFlags(buildCmd, &buildOptions)
 	buildCmd.MarkFlagRequired("manifest")
 	buildCmd.MarkFlagRequired("output")
 }
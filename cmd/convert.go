@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+var (
+	convertInPath  string
+	convertOutPath string
+	convertFrom    string
+	convertTo      string
+	convertOptions manifest.Options
+)
+
+// stdio is the path that stands for standard input or output.
+const stdio = "-"
+
+var convertCmd = &cobra.Command{
+	Use:   "convert",
+	Short: "Converts a manifest to another format.",
+	Long: `This command reads a manifest in any supported format and writes it in
+another, e.g. SubRip subtitles to a text manifest. The input format is
+detected unless --from is given, and the output format follows the extension
+of --out unless --to is given, defaulting to text.
+
+Use "-" for --in or --out to read standard input or write standard output.
+A warning is printed for anything the output format can't hold.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		readOpts := convertOptions
+		writeOpts := convertOptions
+		var err error
+		if convertFrom != "" {
+			if readOpts.Format, err = manifest.ParseFormat(convertFrom); err != nil {
+				log.Fatal(err)
+			}
+		}
+		if convertTo != "" {
+			if writeOpts.Format, err = manifest.ParseFormat(convertTo); err != nil {
+				log.Fatal(err)
+			}
+		} else if f, ok := manifest.FormatFromExtension(convertOutPath); ok && f.Writable() {
+			writeOpts.Format = f
+		} else {
+			writeOpts.Format = manifest.FormatText
+		}
+		if !writeOpts.Format.Writable() {
+			log.Fatalf("Cannot write manifests in %s format", writeOpts.Format)
+		}
+
+		var m *manifest.Manifest
+		if convertInPath == stdio {
+			m, err = manifest.Decode(os.Stdin, "<stdin>", readOpts)
+		} else {
+			m, err = manifest.Load(convertInPath, readOpts)
+		}
+		if err != nil {
+			log.Fatalf("Error reading manifest: %v", err)
+		}
+
+		for _, loss := range manifest.Losses(m, writeOpts) {
+			fmt.Fprintf(os.Stderr, "Warning: %s format doesn't keep %s\n", writeOpts.Format, loss)
+		}
+
+		// The bed is the one path held in a directive; keep it pointing at
+		// the same file from wherever the output is written.
+		outDir := "."
+		if convertOutPath != stdio {
+			outDir = filepath.Dir(convertOutPath)
+		}
+		if bed, ok := m.Directive("bed"); ok {
+			m.SetDirective("bed", manifest.RelativePath(m.ResolvePath(bed), outDir))
+		}
+
+		if convertOutPath == stdio {
+			// As WriteManifest does for files, write clip paths relative to
+			// where the output is read from.
+			for i := range m.Entries {
+				m.Entries[i].FilePath = manifest.RelativePath(m.Entries[i].FilePath, outDir)
+			}
+			err = manifest.Encode(os.Stdout, m, writeOpts)
+		} else {
+			err = manifest.WriteManifest(convertOutPath, m, writeOpts)
+		}
+		if err != nil {
+			log.Fatalf("Error writing manifest: %v", err)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(convertCmd)
+	convertCmd.Flags().StringVarP(&convertInPath, "in", "i", stdio, `Path to the manifest to convert, or "-" for standard input`)
+	convertCmd.Flags().StringVarP(&convertOutPath, "out", "o", stdio, `Path to write the converted manifest to, or "-" for standard output`)
+	convertCmd.Flags().StringVar(&convertFrom, "from", "", "Input format: text, srt, vtt, json, jsonl, rttm, whisper, audacity, csv, tsv or textgrid (default: detected)")
+	convertCmd.Flags().StringVar(&convertTo, "to", "", "Output format: text, vtt, json, jsonl, audacity, csv or tsv (default: from the --out extension, or text)")
+	addManifestFlags(convertCmd, &convertOptions)
+}
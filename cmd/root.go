@@ -1,10 +1,17 @@
 package cmd
 
 import (
+	"context"
+	"encoding/json"
 	"fmt"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"github.com/spf13/cobra"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/pkg/core"
 )
 
 var rootCmd = &cobra.Command{
@@ -16,9 +23,59 @@ files. It can adjust audio speed to match timestamps provided in a manifest file
 
 // Execute adds all child commands to the root command and sets flags appropriately.
 // This is called by main.main(). It only needs to happen once to the rootCmd.
+// SIGINT and SIGTERM cancel the command's context so it can clean up before
+// exiting. A second signal during the cleanup kills the process at once.
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	go func() {
+		// Restore the default handlers as soon as the first signal arrives.
+		<-ctx.Done()
+		stop()
+	}()
+	err := rootCmd.ExecuteContext(ctx)
+	stop()
+	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
 }
+
+// newAudioProcessor returns the audio backend selected with the --backend flag.
+func newAudioProcessor(backend string) (audio.Processor, error) {
+	switch backend {
+	case "ffmpeg":
+		return audio.NewFFmpegProcessor(), nil
+	case "native":
+		return audio.NewNativeProcessor(), nil
+	}
+	return nil, fmt.Errorf("unknown backend %q (expected \"ffmpeg\" or \"native\")", backend)
+}
+
+// addManifestFlags registers the flags that control how manifests are read
+// and written.
+func addManifestFlags(cmd *cobra.Command, opts *manifest.Options) {
+	cmd.Flags().StringVar(&opts.SpeakerPattern, "speaker-pattern", manifest.DefaultSpeakerPattern, "Regular expression for the speaker prefix in subtitle text; the first group is the speaker")
+	cmd.Flags().StringVar(&opts.PathTemplate, "path-template", "", "Template for clip paths of subtitle cues, e.g. clips/{index:03}.wav (default \""+manifest.DefaultPathTemplate+"\", or the label for TextGrids)")
+	cmd.Flags().Var(&opts.FrameRate, "fps", "Video frame rate for timecode: 23.976, 24, 25, 29.97, 29.97df, 30, 50, 59.94 or 59.94df")
+	cmd.Flags().BoolVar(&opts.SnapToFrames, "snap-frames", false, "Move start times to the nearest video frame (requires --fps)")
+	cmd.Flags().StringVar((*string)(&opts.TimeFormat), "time-format", string(manifest.TimeSeconds), "How text manifests are written: seconds, ms, clock (HH:MM:SS.mmm) or timecode (requires --fps)")
+	cmd.Flags().StringVar(&opts.BaseDir, "base-dir", "", "Directory that relative clip paths are resolved against (default: the manifest's directory)")
+	cmd.Flags().BoolVar(&opts.CollectErrors, "all-errors", false, "Report every problem in the manifest instead of stopping at the first")
+	cmd.Flags().StringToStringVar(&opts.Columns, "columns", nil, "Header names of CSV/TSV columns, e.g. start=In,end=Out,speaker=Character,path=File")
+}
+
+// writeReport writes a run report to path as JSON.
+func writeReport(path string, report *core.RunReport) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("failed to create report: %w", err)
+	}
+	defer file.Close()
+
+	enc := json.NewEncoder(file)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		return fmt.Errorf("failed to write report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/pkg/core"
+)
+
+var (
+	validateManifestPath string
+	validateFormat       string
+	validateStrict       bool
+	validateBackend      string
+	validateTimeout      time.Duration
+	validateOptions      manifest.Options
+	validateOverflow     string
+)
+
+var validateCmd = &cobra.Command{
+	Use:   "validate",
+	Short: "Checks a manifest for problems without processing any audio.",
+	Long: `This command lints a manifest before a long run. It reports syntax errors,
+invalid directives, entries that are out of order, overlap or have no
+duration, clips that are missing, unreadable or used twice, clips at a
+sample rate the backend can't convert, and clips whose speed factor would
+fall outside the clamp range. Those speed factors are
+errors unless --overflow names the policy the build will use for them.
+
+It exits with status 1 if any errors are found, or any warnings with --strict.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if validateFormat != "text" && validateFormat != "json" {
+			log.Fatalf("Unknown format %q (expected text or json)", validateFormat)
+		}
+		audioProcessor, err := newAudioProcessor(validateBackend)
+		if err != nil {
+			log.Fatal(err)
+		}
+		opts := []core.Option{
+			core.WithTimeout(validateTimeout),
+			core.WithManifestOptions(validateOptions),
+		}
+		if validateOverflow != "" {
+			overflow, err := core.ParseOverflowPolicy(validateOverflow)
+			if err != nil {
+				log.Fatal(err)
+			}
+			opts = append(opts, core.WithOverflow(overflow))
+		}
+		coreProcessor := core.NewProcessor(audioProcessor, opts...)
+
+		report, err := coreProcessor.Validate(cmd.Context(), validateManifestPath)
+		if err != nil {
+			log.Fatalf("Error during validation: %v", err)
+		}
+		if validateFormat == "json" {
+			enc := json.NewEncoder(os.Stdout)
+			enc.SetIndent("", "  ")
+			if err := enc.Encode(report); err != nil {
+				log.Fatal(err)
+			}
+		} else {
+			report.WriteText(os.Stdout)
+		}
+
+		if report.Errors > 0 || (validateStrict && report.Warnings > 0) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(validateCmd)
+	validateCmd.Flags().StringVarP(&validateManifestPath, "manifest", "m", "", "Path to the manifest file (required)")
+	validateCmd.Flags().StringVar(&validateFormat, "format", "text", "Report format: text or json")
+	validateCmd.Flags().BoolVar(&validateStrict, "strict", false, "Fail on warnings as well as errors")
+	validateCmd.Flags().StringVar(&validateBackend, "backend", "ffmpeg", "Audio backend used to read clip durations: ffmpeg or native (pure Go, WAV only)")
+	validateCmd.Flags().StringVar(&validateOverflow, "overflow", "", "Overflow policy the build will use (push, borrow-gap, overlap, truncate or fail); makes out-of-range speeds warnings")
+	validateCmd.Flags().DurationVar(&validateTimeout, "timeout", 0, "Maximum time for each audio operation, e.g. 10m (0 means no limit)")
+	addManifestFlags(validateCmd, &validateOptions)
+	validateCmd.MarkFlagRequired("manifest")
+}
@@ -1,12 +1,21 @@
 package audio
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"os"
 	"os/exec"
+	"path/filepath"
 	"strconv"
 	"strings"
+	"sync"
 )
 
+// maxFFmpegInputs caps the number of files a single ffmpeg invocation opens,
+// keeping long timelines well below the usual file descriptor limit.
+const maxFFmpegInputs = 256
+
 // Processor is an interface for audio processing operations.
 type Processor interface {
 	GetDuration(filePath string) (float64, error)
@@ -15,20 +24,68 @@ type Processor interface {
 	Concatenate(inputFiles []string, outputFile string) error
 }
 
+// ContextProcessor is a Processor whose operations can be cancelled or given
+// a deadline through a context. A cancelled operation removes its partial output.
+type ContextProcessor interface {
+	Processor
+	GetDurationContext(ctx context.Context, filePath string) (float64, error)
+	ApplySpeedContext(ctx context.Context, inputFile, outputFile string, speed float64) error
+	GenerateSilenceContext(ctx context.Context, duration float64, outputFile string) error
+	ConcatenateContext(ctx context.Context, inputFiles []string, outputFile string) error
+}
+
+// minRenderVersion is the oldest ffmpeg release whose filters the render
+// graph relies on: amix's normalize option came in 4.4, after adelay's
+// sample units and all option.
+var minRenderVersion = [2]int{4, 4}
+
 // FFmpegProcessor implements the AudioProcessor interface using ffmpeg.
-type FFmpegProcessor struct{}
+type FFmpegProcessor struct {
+	// versionMu guards the result of checking the ffmpeg version, which is
+	// only done once rendering is needed.
+	versionMu      sync.Mutex
+	versionChecked bool
+	versionErr     error
+}
 
 // NewFFmpegProcessor creates a new FFmpegProcessor.
 func NewFFmpegProcessor() *FFmpegProcessor {
 	return &FFmpegProcessor{}
 }
 
+// runFFmpeg runs ffmpeg with the given arguments. If it fails or is
+// cancelled, the partially written outputFile is removed.
+func runFFmpeg(ctx context.Context, outputFile string, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "ffmpeg", args...)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		os.Remove(outputFile)
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return output, ctxErr
+		}
+	}
+	return output, err
+}
+
 // GetDuration returns the duration of an audio file in seconds.
 func (p *FFmpegProcessor) GetDuration(filePath string) (float64, error) {
+	return p.GetDurationContext(context.Background(), filePath)
+}
+
+// GetDurationContext returns the duration of an audio file in seconds.
+// PCM WAV files are measured from their header; other containers are probed with ffprobe.
+func (p *FFmpegProcessor) GetDurationContext(ctx context.Context, filePath string) (float64, error) {
+	if info, err := ReadWAVInfo(filePath); err == nil {
+		return info.Duration(), nil
+	}
+
 	// ffprobe -v error -show_entries format=duration -of default=noprint_wrappers=1:nokey=1 <filePath>
-	cmd := exec.Command("ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", filePath)
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-show_entries", "format=duration", "-of", "default=noprint_wrappers=1:nokey=1", filePath)
 	output, err := cmd.CombinedOutput()
 	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return 0, ctxErr
+		}
 		return 0, fmt.Errorf("ffprobe failed with output: %s: %w", string(output), err)
 	}
 
@@ -43,6 +100,11 @@ func (p *FFmpegProcessor) GetDuration(filePath string) (float64, error) {
 
 // ApplySpeed changes the speed of an audio file and saves it to a new file.
 func (p *FFmpegProcessor) ApplySpeed(inputFile, outputFile string, speed float64) error {
+	return p.ApplySpeedContext(context.Background(), inputFile, outputFile, speed)
+}
+
+// ApplySpeedContext changes the speed of an audio file and saves it to a new file.
+func (p *FFmpegProcessor) ApplySpeedContext(ctx context.Context, inputFile, outputFile string, speed float64) error {
 	// Defensive check for the atempo filter's supported range.
 	// The core processor should handle clamping, but this prevents invalid ffmpeg commands.
 	if speed < 0.5 || speed > 2.0 {
@@ -50,9 +112,8 @@ func (p *FFmpegProcessor) ApplySpeed(inputFile, outputFile string, speed float64
 	}
 
 	// ffmpeg -i <inputFile> -filter:a "atempo=<speed>" <outputFile>
-	cmd := exec.Command("ffmpeg", "-y", "-i", inputFile, "-filter:a", fmt.Sprintf("atempo=%.2f", speed), outputFile)
 	// It's important to capture and wrap the error from ffmpeg if it fails.
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := runFFmpeg(ctx, outputFile, "-y", "-i", inputFile, "-filter:a", fmt.Sprintf("atempo=%g", speed), outputFile); err != nil {
 		return fmt.Errorf("ffmpeg failed with output: %s: %w", string(output), err)
 	}
 
@@ -61,9 +122,15 @@ func (p *FFmpegProcessor) ApplySpeed(inputFile, outputFile string, speed float64
 
 // GenerateSilence creates a silent audio file of a given duration.
 func (p *FFmpegProcessor) GenerateSilence(duration float64, outputFile string) error {
-	// ffmpeg -f lavfi -i anullsrc=r=44100:cl=mono -t <duration> <outputFile>
-	cmd := exec.Command("ffmpeg", "-y", "-f", "lavfi", "-i", "anullsrc=r=44100:cl=mono", "-t", fmt.Sprintf("%.3f", duration), outputFile)
-	if output, err := cmd.CombinedOutput(); err != nil {
+	return p.GenerateSilenceContext(context.Background(), duration, outputFile)
+}
+
+// GenerateSilenceContext creates a silent audio file of a given duration.
+func (p *FFmpegProcessor) GenerateSilenceContext(ctx context.Context, duration float64, outputFile string) error {
+	// The length is given in samples so it is exact rather than rounded to the millisecond.
+	// ffmpeg -f lavfi -i anullsrc=r=44100:cl=mono,atrim=end_sample=<samples> <outputFile>
+	samples := int64(math.Round(duration * 44100))
+	if output, err := runFFmpeg(ctx, outputFile, "-y", "-f", "lavfi", "-i", fmt.Sprintf("anullsrc=r=44100:cl=mono,atrim=end_sample=%d", samples), outputFile); err != nil {
 		return fmt.Errorf("ffmpeg failed to generate silence: %s: %w", string(output), err)
 	}
 	return nil
@@ -71,6 +138,11 @@ func (p *FFmpegProcessor) GenerateSilence(duration float64, outputFile string) e
 
 // Concatenate joins multiple audio files into a single file.
 func (p *FFmpegProcessor) Concatenate(inputFiles []string, outputFile string) error {
+	return p.ConcatenateContext(context.Background(), inputFiles, outputFile)
+}
+
+// ConcatenateContext joins multiple audio files into a single file.
+func (p *FFmpegProcessor) ConcatenateContext(ctx context.Context, inputFiles []string, outputFile string) error {
 	if len(inputFiles) == 0 {
 		return fmt.Errorf("no input files provided for concatenation")
 	}
@@ -87,9 +159,245 @@ func (p *FFmpegProcessor) Concatenate(inputFiles []string, outputFile string) er
 	args = append(args, inputs...)
 	args = append(args, "-filter_complex", filterComplex, outputFile)
 
-	cmd := exec.Command("ffmpeg", args...)
-	if output, err := cmd.CombinedOutput(); err != nil {
+	if output, err := runFFmpeg(ctx, outputFile, args...); err != nil {
 		return fmt.Errorf("ffmpeg failed to concatenate files: %s: %w", string(output), err)
 	}
 	return nil
 }
+
+// GetFormat returns the sample format of an audio file.
+func (p *FFmpegProcessor) GetFormat(filePath string) (Format, error) {
+	return p.GetFormatContext(context.Background(), filePath)
+}
+
+// GetFormatContext returns the sample format of an audio file.
+// PCM WAV files are read directly; other containers are probed with ffprobe.
+func (p *FFmpegProcessor) GetFormatContext(ctx context.Context, filePath string) (Format, error) {
+	if info, err := ReadWAVInfo(filePath); err == nil {
+		return info.Format, nil
+	}
+
+	// ffprobe -v error -select_streams a:0 -show_entries stream=sample_rate,channels,bits_per_sample,bits_per_raw_sample -of default=noprint_wrappers=1 <filePath>
+	cmd := exec.CommandContext(ctx, "ffprobe", "-v", "error", "-select_streams", "a:0", "-show_entries", "stream=sample_rate,channels,bits_per_sample,bits_per_raw_sample", "-of", "default=noprint_wrappers=1", filePath)
+	output, err := cmd.CombinedOutput()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return Format{}, ctxErr
+		}
+		return Format{}, fmt.Errorf("ffprobe failed with output: %s: %w", string(output), err)
+	}
+	return parseProbeFormat(string(output))
+}
+
+// parseProbeFormat parses the key=value stream description printed by ffprobe.
+func parseProbeFormat(output string) (Format, error) {
+	var format Format
+	var rawBits int
+	for _, line := range strings.Split(output, "\n") {
+		key, value, ok := strings.Cut(strings.TrimSpace(line), "=")
+		if !ok {
+			continue
+		}
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			continue
+		}
+		switch key {
+		case "sample_rate":
+			format.SampleRate = n
+		case "channels":
+			format.Channels = n
+		case "bits_per_sample":
+			format.BitsPerSample = n
+		case "bits_per_raw_sample":
+			rawBits = n
+		}
+	}
+
+	if format.SampleRate == 0 || format.Channels == 0 {
+		return Format{}, fmt.Errorf("ffprobe did not report an audio stream")
+	}
+	// Compressed codecs report no sample size; they are decoded to 16-bit PCM by default.
+	if format.BitsPerSample == 0 {
+		format.BitsPerSample = rawBits
+	}
+	if format.BitsPerSample == 0 {
+		format.BitsPerSample = 16
+	}
+	return format, nil
+}
+
+// Resamples reports true: ffmpeg converts every clip to the timeline's rate.
+func (p *FFmpegProcessor) Resamples() bool {
+	return true
+}
+
+// Render mixes the clips of a timeline into a single file.
+func (p *FFmpegProcessor) Render(tl Timeline, outputFile string) error {
+	return p.RenderContext(context.Background(), tl, outputFile)
+}
+
+// RenderContext mixes the clips of a timeline into a single file with one
+// ffmpeg filter graph. WAV output is written in the timeline's sample format;
+// other containers get ffmpeg's default codec for them. Very long timelines
+// are rendered as a few intermediate float stems that are then mixed
+// together.
+func (p *FFmpegProcessor) RenderContext(ctx context.Context, tl Timeline, outputFile string) error {
+	if len(tl.Clips) == 0 {
+		return fmt.Errorf("no clips provided for rendering")
+	}
+	if err := p.checkRenderVersion(ctx); err != nil {
+		return err
+	}
+	codec := ""
+	if strings.EqualFold(filepath.Ext(outputFile), ".wav") {
+		codec = pcmCodec(tl.Format)
+	}
+	return p.render(ctx, tl, codec, outputFile)
+}
+
+// checkRenderVersion makes sure the installed ffmpeg is recent enough to run
+// the render graph, which older releases reject with an obscure filter error
+// or mix at the wrong level.
+func (p *FFmpegProcessor) checkRenderVersion(ctx context.Context) error {
+	p.versionMu.Lock()
+	defer p.versionMu.Unlock()
+	if p.versionChecked {
+		return p.versionErr
+	}
+
+	output, err := exec.CommandContext(ctx, "ffmpeg", "-version").Output()
+	if err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return ctxErr
+		}
+		return fmt.Errorf("failed to run ffmpeg -version: %w", err)
+	}
+	p.versionChecked = true
+	if version, ok := parseFFmpegVersion(string(output)); ok && versionBefore(version, minRenderVersion) {
+		p.versionErr = fmt.Errorf("rendering needs ffmpeg %d.%d or later, but ffmpeg %d.%d is installed; upgrade it or use --backend native",
+			minRenderVersion[0], minRenderVersion[1], version[0], version[1])
+	}
+	return p.versionErr
+}
+
+// parseFFmpegVersion reads the major and minor release from the first line of
+// "ffmpeg -version", e.g. "ffmpeg version 4.4.2-0ubuntu0.22.04.1" or
+// "ffmpeg version n6.1". Builds from git name no release and are not
+// recognised.
+func parseFFmpegVersion(output string) ([2]int, bool) {
+	line, _, _ := strings.Cut(output, "\n")
+	rest, ok := strings.CutPrefix(line, "ffmpeg version ")
+	if !ok {
+		return [2]int{}, false
+	}
+	rest = strings.TrimPrefix(rest, "n")
+	end := strings.IndexFunc(rest, func(r rune) bool { return (r < '0' || r > '9') && r != '.' })
+	if end >= 0 {
+		rest = rest[:end]
+	}
+	parts := strings.Split(rest, ".")
+	if len(parts) < 2 {
+		return [2]int{}, false
+	}
+	major, err := strconv.Atoi(parts[0])
+	if err != nil {
+		return [2]int{}, false
+	}
+	minor, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return [2]int{}, false
+	}
+	return [2]int{major, minor}, true
+}
+
+// versionBefore reports whether version v is older than min.
+func versionBefore(v, min [2]int) bool {
+	return v[0] < min[0] || (v[0] == min[0] && v[1] < min[1])
+}
+
+// render mixes a timeline into outputFile encoded with codec, or ffmpeg's
+// default if codec is empty.
+func (p *FFmpegProcessor) render(ctx context.Context, tl Timeline, codec, outputFile string) error {
+	if len(tl.Clips) <= maxFFmpegInputs {
+		if output, err := runFFmpeg(ctx, outputFile, renderArgs(tl, codec, outputFile)...); err != nil {
+			return fmt.Errorf("ffmpeg failed to render timeline: %s: %w", string(output), err)
+		}
+		return nil
+	}
+
+	tempDir, err := os.MkdirTemp("", "sync-audio-render-")
+	if err != nil {
+		return fmt.Errorf("failed to create temp directory: %w", err)
+	}
+	defer os.RemoveAll(tempDir)
+
+	var stems []Clip
+	for start := 0; start < len(tl.Clips); start += maxFFmpegInputs {
+		end := start + maxFFmpegInputs
+		if end > len(tl.Clips) {
+			end = len(tl.Clips)
+		}
+		// Stems are kept as floats so that mixing them again loses nothing.
+		stem := filepath.Join(tempDir, fmt.Sprintf("stem_%d.wav", len(stems)))
+		part := Timeline{Format: tl.Format, Clips: tl.Clips[start:end], Frames: tl.Frames}
+		if err := p.render(ctx, part, "pcm_f32le", stem); err != nil {
+			return err
+		}
+		stems = append(stems, Clip{File: stem})
+	}
+	return p.render(ctx, Timeline{Format: tl.Format, Clips: stems, Frames: tl.Frames}, codec, outputFile)
+}
+
+// pcmCodec returns the ffmpeg PCM encoder for a WAV sample format.
+func pcmCodec(f Format) string {
+	switch {
+	case f.Float && f.BitsPerSample == 64:
+		return "pcm_f64le"
+	case f.Float:
+		return "pcm_f32le"
+	case f.BitsPerSample == 8:
+		return "pcm_u8"
+	case f.BitsPerSample == 24:
+		return "pcm_s24le"
+	case f.BitsPerSample == 32:
+		return "pcm_s32le"
+	}
+	return "pcm_s16le"
+}
+
+// renderArgs builds the ffmpeg command line for a timeline: every clip is
+// converted to the output format, cut and faded if asked, delayed to its
+// offset and summed by amix. The mix is encoded with codec, unless it is
+// empty.
+func renderArgs(tl Timeline, codec, outputFile string) []string {
+	args := []string{"-y"}
+	var graph strings.Builder
+	for i, clip := range tl.Clips {
+		args = append(args, "-i", clip.File)
+		fmt.Fprintf(&graph, "[%d:a]aresample=%d,aformat=sample_fmts=fltp:channel_layouts=%s,",
+			i, tl.Format.SampleRate, tl.Format.ChannelLayout())
+		if clip.Gain != 0 {
+			fmt.Fprintf(&graph, "volume=%sdB,", strconv.FormatFloat(clip.Gain, 'f', -1, 64))
+		}
+		if clip.Frames > 0 {
+			fmt.Fprintf(&graph, "atrim=end_sample=%d,", clip.Frames)
+			if clip.FadeOut > 0 {
+				fmt.Fprintf(&graph, "afade=t=out:start_sample=%d:nb_samples=%d,", clip.Frames-clip.FadeOut, clip.FadeOut)
+			}
+		}
+		fmt.Fprintf(&graph, "adelay=delays=%dS:all=1[c%d];", clip.Offset, i)
+	}
+	for i := range tl.Clips {
+		fmt.Fprintf(&graph, "[c%d]", i)
+	}
+	// normalize=0 sums the inputs as-is instead of scaling each by 1/n.
+	fmt.Fprintf(&graph, "amix=inputs=%d:duration=longest:normalize=0,apad=whole_len=%d,atrim=end_sample=%d[out]",
+		len(tl.Clips), tl.Frames, tl.Frames)
+
+	args = append(args, "-filter_complex", graph.String(), "-map", "[out]")
+	if codec != "" {
+		args = append(args, "-c:a", codec)
+	}
+	return append(args, outputFile)
+}
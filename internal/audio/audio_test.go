@@ -2,6 +2,10 @@ package audio
 
 import (
 	"fmt"
+	"math"
+	"os/exec"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -40,3 +44,217 @@ func TestFFmpegProcessor_ApplySpeed_UnsupportedSpeed(t *testing.T) {
 		t.Error("expected an error for a speed factor greater than 2.0, but got nil")
 	}
 }
+
+func TestRenderArgs(t *testing.T) {
+	tl := Timeline{
+		Format: Format{SampleRate: 48000, Channels: 2, BitsPerSample: 16},
+		Clips:  []Clip{{File: "a.wav", Offset: 0}, {File: "b.wav", Offset: 96000, Gain: -6.5}},
+		Frames: 144000,
+	}
+
+	args := strings.Join(renderArgs(tl, "", "out.wav"), " ")
+	for _, want := range []string{
+		"-i a.wav -i b.wav",
+		"[0:a]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo,adelay=delays=0S:all=1[c0]",
+		"[1:a]aresample=48000,aformat=sample_fmts=fltp:channel_layouts=stereo,volume=-6.5dB,adelay=delays=96000S:all=1[c1]",
+		"[c0][c1]amix=inputs=2:duration=longest:normalize=0,apad=whole_len=144000,atrim=end_sample=144000[out]",
+		"-map [out] out.wav",
+	} {
+		if !strings.Contains(args, want) {
+			t.Errorf("expected ffmpeg arguments to contain %q, got %q", want, args)
+		}
+	}
+}
+
+func TestRenderArgs_CutAndFade(t *testing.T) {
+	tl := Timeline{
+		Format: Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16},
+		Clips:  []Clip{{File: "a.wav", Offset: 480, Frames: 48000, FadeOut: 960}},
+		Frames: 96000,
+	}
+
+	args := strings.Join(renderArgs(tl, "", "out.wav"), " ")
+	want := "atrim=end_sample=48000,afade=t=out:start_sample=47040:nb_samples=960,adelay=delays=480S:all=1[c0]"
+	if !strings.Contains(args, want) {
+		t.Errorf("expected ffmpeg arguments to contain %q, got %q", want, args)
+	}
+}
+
+func TestRenderArgs_Codec(t *testing.T) {
+	testCases := []struct {
+		format Format
+		codec  string
+	}{
+		{Format{BitsPerSample: 8}, "pcm_u8"},
+		{Format{BitsPerSample: 16}, "pcm_s16le"},
+		{Format{BitsPerSample: 24}, "pcm_s24le"},
+		{Format{BitsPerSample: 32}, "pcm_s32le"},
+		{Format{BitsPerSample: 32, Float: true}, "pcm_f32le"},
+		{Format{BitsPerSample: 64, Float: true}, "pcm_f64le"},
+	}
+	for _, tc := range testCases {
+		if codec := pcmCodec(tc.format); codec != tc.codec {
+			t.Errorf("pcmCodec(%+v) = %q, expected %q", tc.format, codec, tc.codec)
+		}
+	}
+
+	tl := Timeline{
+		Format: Format{SampleRate: 48000, Channels: 1, BitsPerSample: 24},
+		Clips:  []Clip{{File: "a.wav"}},
+		Frames: 48000,
+	}
+	args := strings.Join(renderArgs(tl, "pcm_s24le", "out.wav"), " ")
+	if !strings.HasSuffix(args, "-map [out] -c:a pcm_s24le out.wav") {
+		t.Errorf("expected the output to be encoded as pcm_s24le, got %q", args)
+	}
+	if args := strings.Join(renderArgs(tl, "", "out.flac"), " "); strings.Contains(args, "-c:a") {
+		t.Errorf("expected no codec without one given, got %q", args)
+	}
+}
+
+func TestParseProbeFormat(t *testing.T) {
+	format, err := parseProbeFormat("sample_rate=48000\nchannels=2\nbits_per_sample=0\nbits_per_raw_sample=N/A\n")
+	if err != nil {
+		t.Fatalf("parseProbeFormat() error = %v", err)
+	}
+	if format.SampleRate != 48000 || format.Channels != 2 || format.BitsPerSample != 16 {
+		t.Errorf("unexpected format %+v", format)
+	}
+}
+
+func TestParseFFmpegVersion(t *testing.T) {
+	testCases := []struct {
+		output   string
+		expected [2]int
+		ok       bool
+		tooOld   bool
+	}{
+		{"ffmpeg version 4.4.2-0ubuntu0.22.04.1 Copyright (c) 2000-2021 the FFmpeg developers\n", [2]int{4, 4}, true, false},
+		{"ffmpeg version n6.1 Copyright (c) 2000-2023 the FFmpeg developers\n", [2]int{6, 1}, true, false},
+		{"ffmpeg version 4.2.7-0ubuntu0.1 Copyright (c) 2000-2022 the FFmpeg developers\n", [2]int{4, 2}, true, true},
+		{"ffmpeg version 3.4.11 Copyright (c) 2000-2022 the FFmpeg developers\n", [2]int{3, 4}, true, true},
+		{"ffmpeg version N-111111-g0123456789 Copyright (c) 2000-2023 the FFmpeg developers\n", [2]int{}, false, false},
+		{"not ffmpeg\n", [2]int{}, false, false},
+	}
+
+	for _, tc := range testCases {
+		version, ok := parseFFmpegVersion(tc.output)
+		if version != tc.expected || ok != tc.ok {
+			t.Errorf("parseFFmpegVersion(%q) = %v, %v, want %v, %v", tc.output, version, ok, tc.expected, tc.ok)
+		}
+		if ok && versionBefore(version, minRenderVersion) != tc.tooOld {
+			t.Errorf("%v: expected too old = %v", version, tc.tooOld)
+		}
+	}
+}
+
+// requireFFmpeg skips tests that run ffmpeg when it isn't installed.
+func requireFFmpeg(t *testing.T) {
+	t.Helper()
+	if _, err := exec.LookPath("ffmpeg"); err != nil {
+		t.Skip("ffmpeg not found on PATH")
+	}
+}
+
+func TestFFmpegProcessor_Render_MatchesConcatenate(t *testing.T) {
+	requireFFmpeg(t)
+	tmpDir := t.TempDir()
+	processor := NewFFmpegProcessor()
+	format := Format{SampleRate: 44100, Channels: 1, BitsPerSample: 16}
+
+	a := filepath.Join(tmpDir, "a.wav")
+	b := filepath.Join(tmpDir, "b.wav")
+	if err := WriteWAV(a, &Buffer{Format: format, Samples: sine(300, 44100, 30000)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	if err := WriteWAV(b, &Buffer{Format: format, Samples: sine(500, 44100, 12345)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	silence1 := filepath.Join(tmpDir, "s1.wav")
+	silence2 := filepath.Join(tmpDir, "s2.wav")
+	if err := processor.GenerateSilence(0.25, silence1); err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+	if err := processor.GenerateSilence(0.5, silence2); err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+
+	// The build used to concatenate silence and clips like this.
+	concatenated := filepath.Join(tmpDir, "concat.wav")
+	if err := processor.Concatenate([]string{silence1, a, silence2, b}, concatenated); err != nil {
+		t.Fatalf("Concatenate() error = %v", err)
+	}
+
+	rendered := filepath.Join(tmpDir, "render.wav")
+	tl := Timeline{
+		Format: format,
+		Clips: []Clip{
+			{File: a, Offset: 11025},
+			{File: b, Offset: 11025 + 30000 + 22050},
+		},
+		Frames: 11025 + 30000 + 22050 + 12345,
+	}
+	if err := processor.Render(tl, rendered); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want, err := ReadWAV(concatenated)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	got, err := ReadWAV(rendered)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	if len(got.Samples) != len(want.Samples) {
+		t.Fatalf("expected %d samples, got %d", len(want.Samples), len(got.Samples))
+	}
+	for i := range want.Samples {
+		if got.Samples[i] != want.Samples[i] {
+			t.Fatalf("sample %d: expected %v, got %v", i, want.Samples[i], got.Samples[i])
+		}
+	}
+}
+
+func TestFFmpegProcessor_Render_OffsetsAndLevels(t *testing.T) {
+	requireFFmpeg(t)
+	tmpDir := t.TempDir()
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+	clip := filepath.Join(tmpDir, "clip.wav")
+	samples := make([]float64, 100)
+	for i := range samples {
+		samples[i] = 0.25
+	}
+	if err := WriteWAV(clip, &Buffer{Format: format, Samples: samples}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	// The clips overlap from frame 60 to 110, where they must sum rather
+	// than be scaled down by amix.
+	output := filepath.Join(tmpDir, "out.wav")
+	tl := Timeline{Format: format, Clips: []Clip{{File: clip, Offset: 10}, {File: clip, Offset: 60}}, Frames: 200}
+	if err := NewFFmpegProcessor().Render(tl, output); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	buf, err := ReadWAV(output)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	if buf.Frames() != 200 {
+		t.Fatalf("expected 200 frames, got %d", buf.Frames())
+	}
+	for i, s := range buf.Samples {
+		expected := 0.0
+		if i >= 10 && i < 110 {
+			expected += 0.25
+		}
+		if i >= 60 && i < 160 {
+			expected += 0.25
+		}
+		if math.Abs(s-expected) > 1.0/32768 {
+			t.Fatalf("frame %d: expected %v, got %v", i, expected, s)
+		}
+	}
+}
@@ -0,0 +1,415 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// NativeProcessor implements the Processor interface in pure Go, without
+// external tools. It reads and writes PCM WAV files only.
+type NativeProcessor struct {
+	// SilenceFormat is the format of the files written by GenerateSilence.
+	SilenceFormat Format
+}
+
+// NewNativeProcessor creates a new NativeProcessor. Silence is generated as
+// 44.1 kHz mono 16-bit PCM, matching the ffmpeg backend.
+func NewNativeProcessor() *NativeProcessor {
+	return &NativeProcessor{
+		SilenceFormat: Format{SampleRate: 44100, Channels: 1, BitsPerSample: 16},
+	}
+}
+
+// GetDuration returns the duration of a WAV file in seconds.
+func (p *NativeProcessor) GetDuration(filePath string) (float64, error) {
+	return p.GetDurationContext(context.Background(), filePath)
+}
+
+// GetDurationContext returns the duration of a WAV file in seconds.
+func (p *NativeProcessor) GetDurationContext(ctx context.Context, filePath string) (float64, error) {
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	info, err := ReadWAVInfo(filePath)
+	if err != nil {
+		return 0, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	return info.Duration(), nil
+}
+
+// ApplySpeed time-stretches a WAV file by the given factor, preserving pitch.
+func (p *NativeProcessor) ApplySpeed(inputFile, outputFile string, speed float64) error {
+	return p.ApplySpeedContext(context.Background(), inputFile, outputFile, speed)
+}
+
+// ApplySpeedContext time-stretches a WAV file by the given factor, preserving
+// pitch. The output keeps the sample format of the input.
+func (p *NativeProcessor) ApplySpeedContext(ctx context.Context, inputFile, outputFile string, speed float64) error {
+	if speed <= 0 || math.IsInf(speed, 0) || math.IsNaN(speed) {
+		return fmt.Errorf("invalid speed factor %.2f", speed)
+	}
+
+	buf, err := ReadWAV(inputFile)
+	if err != nil {
+		return fmt.Errorf("failed to read input: %w", err)
+	}
+
+	samples, err := timeStretch(ctx, buf.Samples, buf.Format.Channels, buf.Format.SampleRate, speed)
+	if err != nil {
+		return err
+	}
+	stretched := &Buffer{Format: buf.Format, Samples: samples}
+	if err := WriteWAV(outputFile, stretched); err != nil {
+		os.Remove(outputFile)
+		return fmt.Errorf("failed to write output: %w", err)
+	}
+	return nil
+}
+
+// GenerateSilence creates a silent WAV file of a given duration.
+func (p *NativeProcessor) GenerateSilence(duration float64, outputFile string) error {
+	return p.GenerateSilenceContext(context.Background(), duration, outputFile)
+}
+
+// GenerateSilenceContext creates a silent WAV file of a given duration.
+func (p *NativeProcessor) GenerateSilenceContext(ctx context.Context, duration float64, outputFile string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	frames := int64(math.Round(duration * float64(p.SilenceFormat.SampleRate)))
+	if frames < 0 {
+		return fmt.Errorf("invalid silence duration %.3fs", duration)
+	}
+
+	w, err := CreateWAV(outputFile, p.SilenceFormat)
+	if err != nil {
+		return fmt.Errorf("failed to generate silence: %w", err)
+	}
+	if err := writeSilence(w, frames); err != nil {
+		w.Close()
+		os.Remove(outputFile)
+		return fmt.Errorf("failed to generate silence: %w", err)
+	}
+	return w.Close()
+}
+
+// Concatenate joins multiple WAV files into a single file.
+func (p *NativeProcessor) Concatenate(inputFiles []string, outputFile string) error {
+	return p.ConcatenateContext(context.Background(), inputFiles, outputFile)
+}
+
+// ConcatenateContext joins multiple WAV files into a single file. The output
+// uses the format of the first input; later inputs must share its sample
+// rate, and mono inputs are spread across all output channels.
+func (p *NativeProcessor) ConcatenateContext(ctx context.Context, inputFiles []string, outputFile string) error {
+	if len(inputFiles) == 0 {
+		return fmt.Errorf("no input files provided for concatenation")
+	}
+
+	first, err := ReadWAVInfo(inputFiles[0])
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", inputFiles[0], err)
+	}
+
+	w, err := CreateWAV(outputFile, first.Format)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+	for _, file := range inputFiles {
+		if err := ctx.Err(); err != nil {
+			w.Close()
+			os.Remove(outputFile)
+			return err
+		}
+		if err := appendWAV(w, file); err != nil {
+			w.Close()
+			os.Remove(outputFile)
+			return fmt.Errorf("failed to concatenate %s: %w", file, err)
+		}
+	}
+	return w.Close()
+}
+
+// appendWAV streams the samples of the WAV file at path into w.
+func appendWAV(w *WAVWriter, path string) error {
+	r, err := OpenWAV(path)
+	if err != nil {
+		return err
+	}
+	defer r.Close()
+
+	in, out := r.Info().Format, w.Format()
+	if in.SampleRate != out.SampleRate {
+		return fmt.Errorf("sample rate %d Hz does not match output rate %d Hz", in.SampleRate, out.SampleRate)
+	}
+
+	buf := make([]float64, 4096*in.Channels)
+	var mapped []float64
+	for {
+		n, readErr := r.ReadFrames(buf)
+		if n > 0 {
+			var err error
+			if mapped, err = remapChannels(mapped, buf[:n*in.Channels], in.Channels, out.Channels); err != nil {
+				return err
+			}
+			if err := w.WriteFrames(mapped); err != nil {
+				return err
+			}
+		}
+		if readErr == io.EOF {
+			return nil
+		}
+		if readErr != nil {
+			return readErr
+		}
+	}
+}
+
+// remapChannels converts interleaved samples between channel counts. Mono is
+// copied to every channel and any layout can be averaged down to mono; other
+// conversions are rejected.
+func remapChannels(dst, src []float64, from, to int) ([]float64, error) {
+	if from == to {
+		return src, nil
+	}
+	frames := len(src) / from
+	dst = append(dst[:0], make([]float64, frames*to)...)
+	switch {
+	case from == 1:
+		for i := 0; i < frames; i++ {
+			for c := 0; c < to; c++ {
+				dst[i*to+c] = src[i]
+			}
+		}
+	case to == 1:
+		for i := 0; i < frames; i++ {
+			var sum float64
+			for c := 0; c < from; c++ {
+				sum += src[i*from+c]
+			}
+			dst[i] = sum / float64(from)
+		}
+	default:
+		return nil, fmt.Errorf("cannot convert %d channels to %d", from, to)
+	}
+	return dst, nil
+}
+
+// writeSilence writes the given number of zero frames to w.
+func writeSilence(w *WAVWriter, frames int64) error {
+	channels := int64(w.Format().Channels)
+	zeros := make([]float64, 4096*channels)
+	for frames > 0 {
+		n := frames
+		if n > 4096 {
+			n = 4096
+		}
+		if err := w.WriteFrames(zeros[:n*channels]); err != nil {
+			return err
+		}
+		frames -= n
+	}
+	return nil
+}
+
+// GetFormat returns the sample format of a WAV file.
+func (p *NativeProcessor) GetFormat(filePath string) (Format, error) {
+	return p.GetFormatContext(context.Background(), filePath)
+}
+
+// GetFormatContext returns the sample format of a WAV file.
+func (p *NativeProcessor) GetFormatContext(ctx context.Context, filePath string) (Format, error) {
+	if err := ctx.Err(); err != nil {
+		return Format{}, err
+	}
+	info, err := ReadWAVInfo(filePath)
+	if err != nil {
+		return Format{}, fmt.Errorf("failed to read WAV header: %w", err)
+	}
+	return info.Format, nil
+}
+
+// renderBlockFrames is the number of frames the mixer produces per step.
+const renderBlockFrames = 8192
+
+// Resamples reports false: every clip must already be at the timeline's rate.
+func (p *NativeProcessor) Resamples() bool {
+	return false
+}
+
+// Render mixes the clips of a timeline into a single WAV file.
+func (p *NativeProcessor) Render(tl Timeline, outputFile string) error {
+	return p.RenderContext(context.Background(), tl, outputFile)
+}
+
+// RenderContext mixes the clips of a timeline into a single WAV file. Clips
+// are streamed, so only the clips sounding in the current block are held open.
+func (p *NativeProcessor) RenderContext(ctx context.Context, tl Timeline, outputFile string) error {
+	clips := append([]Clip(nil), tl.Clips...)
+	sort.SliceStable(clips, func(i, j int) bool { return clips[i].Offset < clips[j].Offset })
+
+	w, err := CreateWAV(outputFile, tl.Format)
+	if err != nil {
+		return fmt.Errorf("failed to create output: %w", err)
+	}
+
+	var active []*clipStream
+	closeAll := func() {
+		for _, c := range active {
+			c.Close()
+		}
+	}
+	fail := func(err error) error {
+		closeAll()
+		w.Close()
+		os.Remove(outputFile)
+		return err
+	}
+
+	channels := tl.Format.Channels
+	mix := make([]float64, renderBlockFrames*channels)
+	next := 0
+	for pos := int64(0); pos < tl.Frames; pos += renderBlockFrames {
+		if err := ctx.Err(); err != nil {
+			return fail(err)
+		}
+		n := tl.Frames - pos
+		if n > renderBlockFrames {
+			n = renderBlockFrames
+		}
+		block := mix[:n*int64(channels)]
+		for i := range block {
+			block[i] = 0
+		}
+
+		for next < len(clips) && clips[next].Offset < pos+n {
+			c, err := openClipStream(clips[next], tl.Format)
+			if err != nil {
+				return fail(fmt.Errorf("failed to open %s: %w", clips[next].File, err))
+			}
+			active = append(active, c)
+			next++
+		}
+
+		var remaining []*clipStream
+		for _, c := range active {
+			done, err := c.mixInto(block, pos)
+			if err != nil {
+				return fail(fmt.Errorf("failed to read %s: %w", c.clip.File, err))
+			}
+			if done {
+				c.Close()
+				continue
+			}
+			remaining = append(remaining, c)
+		}
+		active = remaining
+
+		if err := w.WriteFrames(block); err != nil {
+			return fail(fmt.Errorf("failed to write output: %w", err))
+		}
+	}
+
+	closeAll()
+	return w.Close()
+}
+
+// clipStream reads a clip incrementally while it is being mixed.
+type clipStream struct {
+	clip     Clip
+	r        *WAVReader
+	channels int
+	out      int
+	gain     float64
+	// read counts the frames of the clip mixed so far.
+	read   int64
+	buf    []float64
+	mapped []float64
+}
+
+func openClipStream(clip Clip, format Format) (*clipStream, error) {
+	r, err := OpenWAV(clip.File)
+	if err != nil {
+		return nil, err
+	}
+	in := r.Info().Format
+	if in.SampleRate != format.SampleRate {
+		r.Close()
+		return nil, fmt.Errorf("sample rate %d Hz does not match output rate %d Hz", in.SampleRate, format.SampleRate)
+	}
+	if in.Channels != format.Channels && in.Channels != 1 && format.Channels != 1 {
+		r.Close()
+		return nil, fmt.Errorf("cannot convert %d channels to %d", in.Channels, format.Channels)
+	}
+	gain := math.Pow(10, clip.Gain/20)
+	return &clipStream{clip: clip, r: r, channels: in.Channels, out: format.Channels, gain: gain}, nil
+}
+
+// mixInto adds the part of the clip that falls within the block starting at
+// frame pos. It reports true once the clip has been fully consumed.
+func (c *clipStream) mixInto(block []float64, pos int64) (bool, error) {
+	frames := int64(len(block) / c.out)
+	start := c.clip.Offset - pos
+	if start < 0 {
+		start = 0
+	}
+	want := int(frames - start)
+	if want <= 0 {
+		return false, nil
+	}
+	if c.clip.Frames > 0 && int64(want) > c.clip.Frames-c.read {
+		want = int(c.clip.Frames - c.read)
+	}
+
+	if cap(c.buf) < want*c.channels {
+		c.buf = make([]float64, want*c.channels)
+	}
+	dst := block[start*int64(c.out):]
+	for want > 0 {
+		n, err := c.r.ReadFrames(c.buf[:want*c.channels])
+		if n > 0 {
+			var mapErr error
+			if c.mapped, mapErr = remapChannels(c.mapped, c.buf[:n*c.channels], c.channels, c.out); mapErr != nil {
+				return false, mapErr
+			}
+			for j := 0; j < n; j++ {
+				gain := c.gain * c.fade(c.read+int64(j))
+				for ch := 0; ch < c.out; ch++ {
+					dst[j*c.out+ch] += c.mapped[j*c.out+ch] * gain
+				}
+			}
+			dst = dst[n*c.out:]
+			want -= n
+			c.read += int64(n)
+		}
+		if err == io.EOF {
+			return true, nil
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return c.clip.Frames > 0 && c.read >= c.clip.Frames, nil
+}
+
+// fade returns the fade-out gain of the clip's frame i.
+func (c *clipStream) fade(i int64) float64 {
+	if c.clip.Frames <= 0 || c.clip.FadeOut <= 0 || i < c.clip.Frames-c.clip.FadeOut {
+		return 1
+	}
+	return float64(c.clip.Frames-i) / float64(c.clip.FadeOut)
+}
+
+// Close releases the clip's file. It is safe to call more than once.
+func (c *clipStream) Close() error {
+	if c.r == nil {
+		return nil
+	}
+	err := c.r.Close()
+	c.r = nil
+	return err
+}
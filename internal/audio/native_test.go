@@ -0,0 +1,290 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// sine returns n frames of a mono sine wave.
+func sine(freq float64, sampleRate, n int) []float64 {
+	samples := make([]float64, n)
+	for i := range samples {
+		samples[i] = 0.5 * math.Sin(2*math.Pi*freq*float64(i)/float64(sampleRate))
+	}
+	return samples
+}
+
+// zeroCrossingRate estimates the frequency of a mono signal from its zero crossings.
+func zeroCrossingRate(samples []float64, sampleRate int) float64 {
+	crossings := 0
+	for i := 1; i < len(samples); i++ {
+		if (samples[i-1] < 0) != (samples[i] < 0) {
+			crossings++
+		}
+	}
+	return float64(crossings) / 2 / (float64(len(samples)) / float64(sampleRate))
+}
+
+func TestTimeStretch_PreservesPitch(t *testing.T) {
+	const sampleRate = 16000
+	in := sine(440, sampleRate, sampleRate*2)
+
+	for _, speed := range []float64{0.9, 1.25, 0.5, 2.0} {
+		out, err := timeStretch(context.Background(), in, 1, sampleRate, speed)
+		if err != nil {
+			t.Fatalf("timeStretch() error = %v", err)
+		}
+
+		expectedFrames := int(math.Round(float64(len(in)) / speed))
+		if len(out) != expectedFrames {
+			t.Errorf("speed %.2f: expected %d frames, got %d", speed, expectedFrames, len(out))
+		}
+		if freq := zeroCrossingRate(out, sampleRate); math.Abs(freq-440) > 10 {
+			t.Errorf("speed %.2f: expected pitch near 440 Hz, got %.1f Hz", speed, freq)
+		}
+	}
+}
+
+func TestNativeProcessor_ApplySpeed(t *testing.T) {
+	tmpDir := t.TempDir()
+	input := filepath.Join(tmpDir, "input.wav")
+	output := filepath.Join(tmpDir, "output.wav")
+
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	if err := WriteWAV(input, &Buffer{Format: format, Samples: sine(220, 8000, 8000)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	processor := NewNativeProcessor()
+	if err := processor.ApplySpeed(input, output, 1.25); err != nil {
+		t.Fatalf("ApplySpeed() error = %v", err)
+	}
+
+	duration, err := processor.GetDuration(output)
+	if err != nil {
+		t.Fatalf("GetDuration() error = %v", err)
+	}
+	if duration != 0.8 {
+		t.Errorf("expected duration 0.8s, got %v", duration)
+	}
+
+	if err := processor.ApplySpeed(input, output, 0); err == nil {
+		t.Error("expected an error for a zero speed factor, but got nil")
+	}
+}
+
+func TestNativeProcessor_Concatenate(t *testing.T) {
+	tmpDir := t.TempDir()
+	silence := filepath.Join(tmpDir, "silence.wav")
+	clip := filepath.Join(tmpDir, "clip.wav")
+	output := filepath.Join(tmpDir, "output.wav")
+
+	processor := NewNativeProcessor()
+	if err := processor.GenerateSilence(0.5, silence); err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+	clipSamples := sine(440, 44100, 4410)
+	if err := WriteWAV(clip, &Buffer{Format: processor.SilenceFormat, Samples: clipSamples}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	if err := processor.Concatenate([]string{silence, clip}, output); err != nil {
+		t.Fatalf("Concatenate() error = %v", err)
+	}
+
+	buf, err := ReadWAV(output)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	if buf.Frames() != 22050+4410 {
+		t.Fatalf("expected %d frames, got %d", 22050+4410, buf.Frames())
+	}
+	for i, s := range buf.Samples[:22050] {
+		if s != 0 {
+			t.Fatalf("expected silence at frame %d, got %v", i, s)
+		}
+	}
+	if math.Abs(buf.Samples[22050+100]-clipSamples[100]) > 1.0/(1<<15) {
+		t.Errorf("clip samples were not copied after the silence")
+	}
+}
+
+func TestNativeProcessor_Concatenate_SampleRateMismatch(t *testing.T) {
+	tmpDir := t.TempDir()
+	a := filepath.Join(tmpDir, "a.wav")
+	b := filepath.Join(tmpDir, "b.wav")
+
+	if err := WriteWAV(a, &Buffer{Format: Format{SampleRate: 44100, Channels: 1, BitsPerSample: 16}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	if err := WriteWAV(b, &Buffer{Format: Format{SampleRate: 48000, Channels: 1, BitsPerSample: 16}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	if err := NewNativeProcessor().Concatenate([]string{a, b}, filepath.Join(tmpDir, "out.wav")); err == nil {
+		t.Error("expected an error for mismatched sample rates, but got nil")
+	}
+}
+
+func TestNativeProcessor_Render_MatchesConcatenate(t *testing.T) {
+	tmpDir := t.TempDir()
+	processor := NewNativeProcessor()
+	format := processor.SilenceFormat
+
+	a := filepath.Join(tmpDir, "a.wav")
+	b := filepath.Join(tmpDir, "b.wav")
+	if err := WriteWAV(a, &Buffer{Format: format, Samples: sine(300, 44100, 30000)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	if err := WriteWAV(b, &Buffer{Format: format, Samples: sine(500, 44100, 12345)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+	silence1 := filepath.Join(tmpDir, "s1.wav")
+	silence2 := filepath.Join(tmpDir, "s2.wav")
+	if err := processor.GenerateSilence(0.25, silence1); err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+	if err := processor.GenerateSilence(0.5, silence2); err != nil {
+		t.Fatalf("GenerateSilence() error = %v", err)
+	}
+
+	concatenated := filepath.Join(tmpDir, "concat.wav")
+	if err := processor.Concatenate([]string{silence1, a, silence2, b}, concatenated); err != nil {
+		t.Fatalf("Concatenate() error = %v", err)
+	}
+
+	rendered := filepath.Join(tmpDir, "render.wav")
+	tl := Timeline{
+		Format: format,
+		Clips: []Clip{
+			{File: a, Offset: 11025},
+			{File: b, Offset: 11025 + 30000 + 22050},
+		},
+		Frames: 11025 + 30000 + 22050 + 12345,
+	}
+	if err := processor.Render(tl, rendered); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	want, err := ReadWAV(concatenated)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	got, err := ReadWAV(rendered)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	if len(got.Samples) != len(want.Samples) {
+		t.Fatalf("expected %d samples, got %d", len(want.Samples), len(got.Samples))
+	}
+	for i := range want.Samples {
+		if got.Samples[i] != want.Samples[i] {
+			t.Fatalf("sample %d: expected %v, got %v", i, want.Samples[i], got.Samples[i])
+		}
+	}
+}
+
+func TestNativeProcessor_Render_MixesOverlaps(t *testing.T) {
+	tmpDir := t.TempDir()
+	format := Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+
+	clip := filepath.Join(tmpDir, "clip.wav")
+	mono := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	if err := WriteWAV(clip, &Buffer{Format: mono, Samples: []float64{0.25, 0.25, 0.25, 0.25}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	output := filepath.Join(tmpDir, "out.wav")
+	tl := Timeline{Format: format, Clips: []Clip{{File: clip, Offset: 1}, {File: clip, Offset: 3}}, Frames: 8}
+	if err := NewNativeProcessor().Render(tl, output); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	buf, err := ReadWAV(output)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	expected := []float64{0, 0.25, 0.25, 0.5, 0.5, 0.25, 0.25, 0}
+	for i, e := range expected {
+		if buf.Samples[2*i] != e || buf.Samples[2*i+1] != e {
+			t.Errorf("frame %d: expected %v, got %v", i, e, buf.Samples[2*i:2*i+2])
+		}
+	}
+}
+
+func TestNativeProcessor_Render_Gain(t *testing.T) {
+	tmpDir := t.TempDir()
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+	clip := filepath.Join(tmpDir, "clip.wav")
+	if err := WriteWAV(clip, &Buffer{Format: format, Samples: []float64{0.5, 0.5}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	output := filepath.Join(tmpDir, "out.wav")
+	tl := Timeline{Format: format, Clips: []Clip{{File: clip, Gain: -20 * math.Log10(2)}}, Frames: 2}
+	if err := NewNativeProcessor().Render(tl, output); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	buf, err := ReadWAV(output)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	for i, s := range buf.Samples {
+		if s != 0.25 {
+			t.Errorf("frame %d: expected 0.25, got %v", i, s)
+		}
+	}
+}
+
+func TestNativeProcessor_Render_CutAndFade(t *testing.T) {
+	tmpDir := t.TempDir()
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+
+	clip := filepath.Join(tmpDir, "clip.wav")
+	if err := WriteWAV(clip, &Buffer{Format: format, Samples: []float64{0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5, 0.5}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	output := filepath.Join(tmpDir, "out.wav")
+	tl := Timeline{Format: format, Clips: []Clip{{File: clip, Offset: 1, Frames: 5, FadeOut: 2}}, Frames: 8}
+	if err := NewNativeProcessor().Render(tl, output); err != nil {
+		t.Fatalf("Render() error = %v", err)
+	}
+
+	buf, err := ReadWAV(output)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	expected := []float64{0, 0.5, 0.5, 0.5, 0.5, 0.25, 0, 0}
+	for i, e := range expected {
+		if math.Abs(buf.Samples[i]-e) > 1e-4 {
+			t.Errorf("frame %d: expected %v, got %v", i, e, buf.Samples[i])
+		}
+	}
+}
+
+func TestNativeProcessor_RenderContext_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	clip := filepath.Join(tmpDir, "clip.wav")
+	if err := WriteWAV(clip, &Buffer{Format: format, Samples: sine(440, 8000, 8000)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	output := filepath.Join(tmpDir, "out.wav")
+	tl := Timeline{Format: format, Clips: []Clip{{File: clip}}, Frames: 8000}
+	if err := NewNativeProcessor().RenderContext(ctx, tl, output); err != context.Canceled {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(output); !os.IsNotExist(err) {
+		t.Error("expected the partial output to be removed")
+	}
+}
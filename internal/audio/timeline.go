@@ -0,0 +1,50 @@
+package audio
+
+import "context"
+
+// Clip is an audio file placed on a timeline.
+type Clip struct {
+	File string
+	// Offset is the position of the clip's first sample, in frames at the
+	// timeline's sample rate.
+	Offset int64
+	// Gain is applied to the clip before mixing, in decibels.
+	Gain float64
+	// Frames cuts the clip after that many frames, if positive.
+	Frames int64
+	// FadeOut fades the last FadeOut frames before the cut to silence. It
+	// needs Frames to be set.
+	FadeOut int64
+}
+
+// Timeline describes an output track as clips at absolute sample positions.
+// Anything not covered by a clip is silence, and overlapping clips are mixed.
+type Timeline struct {
+	Format Format
+	Clips  []Clip
+	// Frames is the length of the track; clips running past it are cut.
+	Frames int64
+}
+
+// Renderer is implemented by backends that can render a whole timeline in a
+// single pass, rather than concatenating the track piece by piece.
+type Renderer interface {
+	// GetFormat returns the sample format of an audio file.
+	GetFormat(filePath string) (Format, error)
+	Render(tl Timeline, outputFile string) error
+}
+
+// Resampler is implemented by renderers that report whether they can mix
+// clips whose sample rate differs from the timeline's. Renderers that don't
+// implement it are assumed not to.
+type Resampler interface {
+	Resamples() bool
+}
+
+// ContextRenderer is a Renderer whose operations can be cancelled or given a
+// deadline through a context. A cancelled render removes its partial output.
+type ContextRenderer interface {
+	Renderer
+	GetFormatContext(ctx context.Context, filePath string) (Format, error)
+	RenderContext(ctx context.Context, tl Timeline, outputFile string) error
+}
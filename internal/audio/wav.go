@@ -0,0 +1,637 @@
+package audio
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+)
+
+// WAVE format tags, as found in the fmt chunk or the first two bytes of the
+// WAVE_FORMAT_EXTENSIBLE sub-format GUID.
+const (
+	wavFormatPCM        = 0x0001
+	wavFormatIEEEFloat  = 0x0003
+	wavFormatExtensible = 0xFFFE
+)
+
+// unknownSize marks a 32-bit chunk size that is either stored in the ds64
+// chunk (RF64) or was never patched by a streaming writer.
+const unknownSize = 0xFFFFFFFF
+
+// ds64ChunkSize is the payload of a ds64 chunk without a size table. The
+// writer reserves this much space in a JUNK chunk so a file can be promoted
+// to RF64 once it outgrows 4 GiB.
+const ds64ChunkSize = 28
+
+var (
+	// ErrNotWAV is returned when a file does not start with a RIFF/RF64 WAVE header.
+	ErrNotWAV = errors.New("not a WAV file")
+	// ErrUnsupportedFormat is returned for WAV files whose codec is not plain PCM or IEEE float.
+	ErrUnsupportedFormat = errors.New("unsupported WAV format")
+)
+
+// Format describes the sample layout of PCM audio.
+type Format struct {
+	SampleRate    int
+	Channels      int
+	BitsPerSample int
+	// Float reports whether samples are IEEE floats rather than integers.
+	Float bool
+	// ChannelMask is the WAVE_FORMAT_EXTENSIBLE speaker mask, or zero if the
+	// file does not declare one.
+	ChannelMask uint32
+}
+
+// BlockAlign returns the size of one frame (a sample for every channel) in bytes.
+func (f Format) BlockAlign() int {
+	return f.Channels * f.BitsPerSample / 8
+}
+
+// ChannelLayout returns an ffmpeg-style name for the channel layout.
+func (f Format) ChannelLayout() string {
+	switch f.ChannelMask {
+	case 0x4:
+		return "mono"
+	case 0x3:
+		return "stereo"
+	case 0x3F:
+		return "5.1"
+	case 0x60F:
+		return "5.1(side)"
+	case 0x63F:
+		return "7.1"
+	}
+	switch f.Channels {
+	case 1:
+		return "mono"
+	case 2:
+		return "stereo"
+	}
+	return fmt.Sprintf("%dc", f.Channels)
+}
+
+func (f Format) validate() error {
+	if f.SampleRate <= 0 || f.Channels <= 0 {
+		return fmt.Errorf("%w: %d Hz, %d channels", ErrUnsupportedFormat, f.SampleRate, f.Channels)
+	}
+	switch {
+	case f.Float && (f.BitsPerSample == 32 || f.BitsPerSample == 64):
+	case !f.Float && (f.BitsPerSample == 8 || f.BitsPerSample == 16 || f.BitsPerSample == 24 || f.BitsPerSample == 32):
+	default:
+		kind := "integer"
+		if f.Float {
+			kind = "float"
+		}
+		return fmt.Errorf("%w: %d-bit %s samples", ErrUnsupportedFormat, f.BitsPerSample, kind)
+	}
+	return nil
+}
+
+// WAVInfo describes the contents of a WAV file as declared by its header.
+type WAVInfo struct {
+	Format
+	// Frames is the number of sample frames in the data chunk.
+	Frames int64
+}
+
+// Duration returns the length of the audio in seconds.
+func (i WAVInfo) Duration() float64 {
+	return float64(i.Frames) / float64(i.SampleRate)
+}
+
+// ReadWAVInfo reads only the header of the WAV file at path.
+func ReadWAVInfo(path string) (WAVInfo, error) {
+	r, err := OpenWAV(path)
+	if err != nil {
+		return WAVInfo{}, err
+	}
+	defer r.Close()
+	return r.Info(), nil
+}
+
+// WAVReader decodes PCM samples from a RIFF or RF64 WAVE stream.
+type WAVReader struct {
+	info      WAVInfo
+	r         *bufio.Reader
+	closer    io.Closer
+	remaining int64
+	buf       []byte
+}
+
+// OpenWAV opens the WAV file at path for reading. The caller must Close it.
+func OpenWAV(path string) (*WAVReader, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	r, err := NewWAVReader(file)
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	r.closer = file
+
+	// Streaming writers leave the data size unset and truncated files claim
+	// more data than they hold; in both cases the file size is authoritative.
+	if stat, err := file.Stat(); err == nil {
+		if offset, err := file.Seek(0, io.SeekCurrent); err == nil {
+			available := (stat.Size() - offset + int64(r.r.Buffered())) / int64(r.info.BlockAlign())
+			if r.info.Frames < 0 || r.info.Frames > available {
+				r.info.Frames = available
+				r.remaining = available
+			}
+		}
+	}
+	if r.info.Frames < 0 {
+		file.Close()
+		return nil, fmt.Errorf("%s: data chunk has no size", path)
+	}
+
+	return r, nil
+}
+
+// NewWAVReader parses the WAV header from r and positions it at the first
+// sample. Info().Frames is -1 when the header does not record the data size.
+func NewWAVReader(r io.Reader) (*WAVReader, error) {
+	br := bufio.NewReader(r)
+
+	var riff [12]byte
+	if _, err := io.ReadFull(br, riff[:]); err != nil {
+		return nil, ErrNotWAV
+	}
+	id := string(riff[0:4])
+	if (id != "RIFF" && id != "RF64") || string(riff[8:12]) != "WAVE" {
+		return nil, ErrNotWAV
+	}
+	isRF64 := id == "RF64"
+
+	var (
+		format     Format
+		haveFormat bool
+		dataSize64 int64 = -1
+	)
+	for {
+		var header [8]byte
+		if _, err := io.ReadFull(br, header[:]); err != nil {
+			return nil, fmt.Errorf("missing data chunk: %w", err)
+		}
+		chunkID := string(header[0:4])
+		size := binary.LittleEndian.Uint32(header[4:8])
+
+		switch chunkID {
+		case "ds64":
+			payload, err := readChunk(br, size)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read ds64 chunk: %w", err)
+			}
+			if len(payload) < 16 {
+				return nil, fmt.Errorf("ds64 chunk too short (%d bytes)", len(payload))
+			}
+			dataSize64 = int64(binary.LittleEndian.Uint64(payload[8:16]))
+
+		case "fmt ":
+			payload, err := readChunk(br, size)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read fmt chunk: %w", err)
+			}
+			if format, err = parseFmtChunk(payload); err != nil {
+				return nil, err
+			}
+			haveFormat = true
+
+		case "data":
+			if !haveFormat {
+				return nil, fmt.Errorf("data chunk precedes fmt chunk")
+			}
+			var dataSize int64
+			switch {
+			case isRF64 && size == unknownSize && dataSize64 >= 0:
+				dataSize = dataSize64
+			case size == unknownSize:
+				dataSize = -1
+			default:
+				dataSize = int64(size)
+			}
+
+			frames := int64(-1)
+			if dataSize >= 0 {
+				frames = dataSize / int64(format.BlockAlign())
+			}
+			return &WAVReader{
+				info:      WAVInfo{Format: format, Frames: frames},
+				r:         br,
+				remaining: frames,
+			}, nil
+
+		default:
+			if _, err := br.Discard(int(size) + int(size&1)); err != nil {
+				return nil, fmt.Errorf("failed to skip %q chunk: %w", chunkID, err)
+			}
+		}
+	}
+}
+
+// readChunk reads a chunk payload and its pad byte, if any.
+func readChunk(r *bufio.Reader, size uint32) ([]byte, error) {
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+	if size&1 == 1 {
+		if _, err := r.Discard(1); err != nil {
+			return nil, err
+		}
+	}
+	return payload, nil
+}
+
+func parseFmtChunk(payload []byte) (Format, error) {
+	if len(payload) < 16 {
+		return Format{}, fmt.Errorf("fmt chunk too short (%d bytes)", len(payload))
+	}
+
+	tag := binary.LittleEndian.Uint16(payload[0:2])
+	format := Format{
+		Channels:      int(binary.LittleEndian.Uint16(payload[2:4])),
+		SampleRate:    int(binary.LittleEndian.Uint32(payload[4:8])),
+		BitsPerSample: int(binary.LittleEndian.Uint16(payload[14:16])),
+	}
+
+	if tag == wavFormatExtensible {
+		if len(payload) < 40 {
+			return Format{}, fmt.Errorf("extensible fmt chunk too short (%d bytes)", len(payload))
+		}
+		format.ChannelMask = binary.LittleEndian.Uint32(payload[20:24])
+		tag = binary.LittleEndian.Uint16(payload[24:26])
+	}
+
+	switch tag {
+	case wavFormatPCM:
+	case wavFormatIEEEFloat:
+		format.Float = true
+	default:
+		return Format{}, fmt.Errorf("%w: format tag 0x%04X", ErrUnsupportedFormat, tag)
+	}
+
+	if err := format.validate(); err != nil {
+		return Format{}, err
+	}
+	return format, nil
+}
+
+// Info returns the format and length of the stream.
+func (r *WAVReader) Info() WAVInfo {
+	return r.info
+}
+
+// ReadFrames decodes up to len(dst)/Channels frames of interleaved samples
+// into dst, scaled to the range [-1, 1). It returns the number of frames
+// read and io.EOF once the data chunk is exhausted.
+func (r *WAVReader) ReadFrames(dst []float64) (int, error) {
+	channels := r.info.Channels
+	blockAlign := r.info.BlockAlign()
+
+	frames := len(dst) / channels
+	if r.remaining >= 0 && int64(frames) > r.remaining {
+		frames = int(r.remaining)
+	}
+	if frames == 0 {
+		return 0, io.EOF
+	}
+
+	need := frames * blockAlign
+	if cap(r.buf) < need {
+		r.buf = make([]byte, need)
+	}
+	buf := r.buf[:need]
+
+	n, err := io.ReadFull(r.r, buf)
+	frames = n / blockAlign
+	if frames == 0 {
+		if err == io.ErrUnexpectedEOF {
+			err = io.EOF
+		}
+		return 0, err
+	}
+
+	decodeSamples(dst[:frames*channels], buf[:frames*blockAlign], r.info.Format)
+	if r.remaining >= 0 {
+		r.remaining -= int64(frames)
+	}
+	return frames, nil
+}
+
+// Close releases the underlying file, if the reader owns one.
+func (r *WAVReader) Close() error {
+	if r.closer != nil {
+		return r.closer.Close()
+	}
+	return nil
+}
+
+func decodeSamples(dst []float64, src []byte, format Format) {
+	switch {
+	case format.Float && format.BitsPerSample == 32:
+		for i := range dst {
+			dst[i] = float64(math.Float32frombits(binary.LittleEndian.Uint32(src[4*i:])))
+		}
+	case format.Float:
+		for i := range dst {
+			dst[i] = math.Float64frombits(binary.LittleEndian.Uint64(src[8*i:]))
+		}
+	case format.BitsPerSample == 8:
+		for i := range dst {
+			dst[i] = float64(int(src[i])-128) / 128
+		}
+	case format.BitsPerSample == 16:
+		for i := range dst {
+			dst[i] = float64(int16(binary.LittleEndian.Uint16(src[2*i:]))) / (1 << 15)
+		}
+	case format.BitsPerSample == 24:
+		for i := range dst {
+			b := src[3*i:]
+			v := int32(uint32(b[0])<<8|uint32(b[1])<<16|uint32(b[2])<<24) >> 8
+			dst[i] = float64(v) / (1 << 23)
+		}
+	case format.BitsPerSample == 32:
+		for i := range dst {
+			dst[i] = float64(int32(binary.LittleEndian.Uint32(src[4*i:]))) / (1 << 31)
+		}
+	}
+}
+
+// WAVWriter encodes PCM samples to a WAV file. Files are written as plain
+// RIFF and promoted to RF64 on Close if the data outgrows 4 GiB.
+type WAVWriter struct {
+	file      *os.File
+	w         *bufio.Writer
+	format    Format
+	extended  bool
+	dataBytes int64
+	buf       []byte
+}
+
+// CreateWAV creates (or truncates) the file at path and writes a WAV header
+// for the given format. The caller must Close the writer to finalize the file.
+func CreateWAV(path string, format Format) (*WAVWriter, error) {
+	if err := format.validate(); err != nil {
+		return nil, err
+	}
+
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+
+	w := &WAVWriter{
+		file:   file,
+		w:      bufio.NewWriter(file),
+		format: format,
+		// Microsoft requires WAVE_FORMAT_EXTENSIBLE for more than two
+		// channels or integer samples wider than 16 bits.
+		extended: format.Channels > 2 || (!format.Float && format.BitsPerSample > 16) || format.ChannelMask != 0,
+	}
+	if _, err := w.w.Write(w.header(0)); err != nil {
+		file.Close()
+		return nil, err
+	}
+	return w, nil
+}
+
+// header renders everything up to the first data byte for a data chunk of the given size.
+func (w *WAVWriter) header(dataBytes int64) []byte {
+	fmtSize := 16
+	if w.extended {
+		fmtSize = 40
+	}
+	headerSize := 12 + 8 + ds64ChunkSize + 8 + fmtSize + 8
+	riffSize := int64(headerSize) - 8 + dataBytes + dataBytes&1
+	rf64 := riffSize > math.MaxUint32-1
+
+	h := make([]byte, 0, headerSize)
+	le := binary.LittleEndian
+
+	if rf64 {
+		h = append(h, "RF64"...)
+		h = le.AppendUint32(h, unknownSize)
+	} else {
+		h = append(h, "RIFF"...)
+		h = le.AppendUint32(h, uint32(riffSize))
+	}
+	h = append(h, "WAVE"...)
+
+	if rf64 {
+		h = append(h, "ds64"...)
+		h = le.AppendUint32(h, ds64ChunkSize)
+		h = le.AppendUint64(h, uint64(riffSize))
+		h = le.AppendUint64(h, uint64(dataBytes))
+		h = le.AppendUint64(h, uint64(dataBytes/int64(w.format.BlockAlign())))
+		h = le.AppendUint32(h, 0)
+	} else {
+		h = append(h, "JUNK"...)
+		h = le.AppendUint32(h, ds64ChunkSize)
+		h = append(h, make([]byte, ds64ChunkSize)...)
+	}
+
+	tag := uint16(wavFormatPCM)
+	if w.format.Float {
+		tag = wavFormatIEEEFloat
+	}
+	blockAlign := w.format.BlockAlign()
+
+	h = append(h, "fmt "...)
+	h = le.AppendUint32(h, uint32(fmtSize))
+	if w.extended {
+		h = le.AppendUint16(h, wavFormatExtensible)
+	} else {
+		h = le.AppendUint16(h, tag)
+	}
+	h = le.AppendUint16(h, uint16(w.format.Channels))
+	h = le.AppendUint32(h, uint32(w.format.SampleRate))
+	h = le.AppendUint32(h, uint32(w.format.SampleRate*blockAlign))
+	h = le.AppendUint16(h, uint16(blockAlign))
+	h = le.AppendUint16(h, uint16(w.format.BitsPerSample))
+	if w.extended {
+		mask := w.format.ChannelMask
+		if mask == 0 {
+			mask = defaultChannelMask(w.format.Channels)
+		}
+		h = le.AppendUint16(h, 22)
+		h = le.AppendUint16(h, uint16(w.format.BitsPerSample))
+		h = le.AppendUint32(h, mask)
+		// KSDATAFORMAT_SUBTYPE_PCM / _IEEE_FLOAT: {0000000X-0000-0010-8000-00AA00389B71}
+		h = le.AppendUint16(h, tag)
+		h = append(h, 0x00, 0x00, 0x00, 0x00, 0x10, 0x00, 0x80, 0x00, 0x00, 0xAA, 0x00, 0x38, 0x9B, 0x71)
+	}
+
+	h = append(h, "data"...)
+	if rf64 {
+		h = le.AppendUint32(h, unknownSize)
+	} else {
+		h = le.AppendUint32(h, uint32(dataBytes))
+	}
+	return h
+}
+
+// defaultChannelMask returns the conventional speaker mask for a channel count.
+func defaultChannelMask(channels int) uint32 {
+	switch channels {
+	case 1:
+		return 0x4
+	case 2:
+		return 0x3
+	case 6:
+		return 0x3F
+	case 8:
+		return 0x63F
+	}
+	return 0
+}
+
+// Format returns the format the writer encodes.
+func (w *WAVWriter) Format() Format {
+	return w.format
+}
+
+// WriteFrames encodes interleaved samples in the range [-1, 1]. Integer
+// formats are rounded and clipped; len(samples) must be a whole number of frames.
+func (w *WAVWriter) WriteFrames(samples []float64) error {
+	if len(samples)%w.format.Channels != 0 {
+		return fmt.Errorf("sample count %d is not a multiple of %d channels", len(samples), w.format.Channels)
+	}
+
+	need := len(samples) * w.format.BitsPerSample / 8
+	if cap(w.buf) < need {
+		w.buf = make([]byte, need)
+	}
+	buf := w.buf[:need]
+	encodeSamples(buf, samples, w.format)
+
+	n, err := w.w.Write(buf)
+	w.dataBytes += int64(n)
+	return err
+}
+
+// Close flushes buffered samples, patches the header sizes and closes the file.
+func (w *WAVWriter) Close() error {
+	err := w.finish()
+	if cerr := w.file.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+func (w *WAVWriter) finish() error {
+	if w.dataBytes&1 == 1 {
+		if err := w.w.WriteByte(0); err != nil {
+			return err
+		}
+	}
+	if err := w.w.Flush(); err != nil {
+		return err
+	}
+	_, err := w.file.WriteAt(w.header(w.dataBytes), 0)
+	return err
+}
+
+func encodeSamples(dst []byte, src []float64, format Format) {
+	switch {
+	case format.Float && format.BitsPerSample == 32:
+		for i, s := range src {
+			binary.LittleEndian.PutUint32(dst[4*i:], math.Float32bits(float32(s)))
+		}
+	case format.Float:
+		for i, s := range src {
+			binary.LittleEndian.PutUint64(dst[8*i:], math.Float64bits(s))
+		}
+	case format.BitsPerSample == 8:
+		for i, s := range src {
+			dst[i] = byte(quantize(s, 7) + 128)
+		}
+	case format.BitsPerSample == 16:
+		for i, s := range src {
+			binary.LittleEndian.PutUint16(dst[2*i:], uint16(quantize(s, 15)))
+		}
+	case format.BitsPerSample == 24:
+		for i, s := range src {
+			v := uint32(quantize(s, 23))
+			dst[3*i], dst[3*i+1], dst[3*i+2] = byte(v), byte(v>>8), byte(v>>16)
+		}
+	case format.BitsPerSample == 32:
+		for i, s := range src {
+			binary.LittleEndian.PutUint32(dst[4*i:], uint32(quantize(s, 31)))
+		}
+	}
+}
+
+// quantize scales s to a signed integer with the given number of magnitude
+// bits, rounding to nearest and clipping to the representable range.
+func quantize(s float64, bits uint) int64 {
+	scale := float64(int64(1) << bits)
+	v := math.Round(s * scale)
+	if v > scale-1 {
+		return int64(scale - 1)
+	}
+	if v < -scale {
+		return int64(-scale)
+	}
+	return int64(v)
+}
+
+// Buffer holds decoded audio in memory as interleaved samples.
+type Buffer struct {
+	Format  Format
+	Samples []float64
+}
+
+// Frames returns the number of sample frames in the buffer.
+func (b *Buffer) Frames() int {
+	return len(b.Samples) / b.Format.Channels
+}
+
+// ReadWAV decodes the whole WAV file at path into memory.
+func ReadWAV(path string) (*Buffer, error) {
+	r, err := OpenWAV(path)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	info := r.Info()
+	buf := &Buffer{
+		Format:  info.Format,
+		Samples: make([]float64, info.Frames*int64(info.Channels)),
+	}
+
+	read := 0
+	for read < len(buf.Samples) {
+		n, err := r.ReadFrames(buf.Samples[read:])
+		read += n * info.Channels
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	buf.Samples = buf.Samples[:read]
+	return buf, nil
+}
+
+// WriteWAV encodes buf to a WAV file at path.
+func WriteWAV(path string, buf *Buffer) error {
+	w, err := CreateWAV(path, buf.Format)
+	if err != nil {
+		return err
+	}
+	if err := w.WriteFrames(buf.Samples); err != nil {
+		w.Close()
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,146 @@
+package audio
+
+import (
+	"encoding/binary"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWAV_RoundTrip(t *testing.T) {
+	testCases := []struct {
+		name   string
+		format Format
+	}{
+		{"16-bit mono", Format{SampleRate: 44100, Channels: 1, BitsPerSample: 16}},
+		{"24-bit stereo", Format{SampleRate: 48000, Channels: 2, BitsPerSample: 24, ChannelMask: 0x3}},
+		{"32-bit int stereo", Format{SampleRate: 48000, Channels: 2, BitsPerSample: 32, ChannelMask: 0x3}},
+		{"32-bit float mono", Format{SampleRate: 22050, Channels: 1, BitsPerSample: 32, Float: true}},
+		{"16-bit 5.1 extensible", Format{SampleRate: 48000, Channels: 6, BitsPerSample: 16, ChannelMask: 0x3F}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			// Values that are exactly representable in every format under test.
+			samples := make([]float64, 101*tc.format.Channels)
+			for i := range samples {
+				samples[i] = float64(int16(i*977)) / (1 << 15)
+			}
+
+			path := filepath.Join(t.TempDir(), "test.wav")
+			if err := WriteWAV(path, &Buffer{Format: tc.format, Samples: samples}); err != nil {
+				t.Fatalf("WriteWAV() error = %v", err)
+			}
+
+			info, err := ReadWAVInfo(path)
+			if err != nil {
+				t.Fatalf("ReadWAVInfo() error = %v", err)
+			}
+			if info.Format != tc.format {
+				t.Errorf("expected format %+v, got %+v", tc.format, info.Format)
+			}
+			if info.Frames != 101 {
+				t.Errorf("expected 101 frames, got %d", info.Frames)
+			}
+
+			buf, err := ReadWAV(path)
+			if err != nil {
+				t.Fatalf("ReadWAV() error = %v", err)
+			}
+			if len(buf.Samples) != len(samples) {
+				t.Fatalf("expected %d samples, got %d", len(samples), len(buf.Samples))
+			}
+			for i := range samples {
+				if buf.Samples[i] != samples[i] {
+					t.Fatalf("sample %d: expected %v, got %v", i, samples[i], buf.Samples[i])
+				}
+			}
+		})
+	}
+}
+
+func TestWAVInfo_Duration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wav")
+	format := Format{SampleRate: 8000, Channels: 2, BitsPerSample: 16}
+	if err := WriteWAV(path, &Buffer{Format: format, Samples: make([]float64, 12000*2)}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	info, err := ReadWAVInfo(path)
+	if err != nil {
+		t.Fatalf("ReadWAVInfo() error = %v", err)
+	}
+	if info.Duration() != 1.5 {
+		t.Errorf("expected duration 1.5s, got %v", info.Duration())
+	}
+	if info.ChannelLayout() != "stereo" {
+		t.Errorf("expected stereo layout, got %s", info.ChannelLayout())
+	}
+}
+
+func TestReadWAVInfo_RF64(t *testing.T) {
+	le := binary.LittleEndian
+	var b []byte
+	b = append(b, "RF64"...)
+	b = le.AppendUint32(b, unknownSize)
+	b = append(b, "WAVE"...)
+	b = append(b, "ds64"...)
+	b = le.AppendUint32(b, ds64ChunkSize)
+	b = le.AppendUint64(b, 0)
+	b = le.AppendUint64(b, 8) // data size
+	b = le.AppendUint64(b, 4) // sample count
+	b = le.AppendUint32(b, 0)
+	b = append(b, "fmt "...)
+	b = le.AppendUint32(b, 16)
+	b = le.AppendUint16(b, wavFormatPCM)
+	b = le.AppendUint16(b, 1)
+	b = le.AppendUint32(b, 16000)
+	b = le.AppendUint32(b, 32000)
+	b = le.AppendUint16(b, 2)
+	b = le.AppendUint16(b, 16)
+	b = append(b, "data"...)
+	b = le.AppendUint32(b, unknownSize)
+	b = append(b, make([]byte, 8)...)
+
+	path := filepath.Join(t.TempDir(), "test.wav")
+	if err := os.WriteFile(path, b, 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	info, err := ReadWAVInfo(path)
+	if err != nil {
+		t.Fatalf("ReadWAVInfo() error = %v", err)
+	}
+	if info.Frames != 4 || info.SampleRate != 16000 {
+		t.Errorf("expected 4 frames at 16000 Hz, got %d at %d Hz", info.Frames, info.SampleRate)
+	}
+}
+
+func TestWAVWriter_ClipsOutOfRange(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.wav")
+	format := Format{SampleRate: 8000, Channels: 1, BitsPerSample: 16}
+	if err := WriteWAV(path, &Buffer{Format: format, Samples: []float64{2, -2}}); err != nil {
+		t.Fatalf("WriteWAV() error = %v", err)
+	}
+
+	buf, err := ReadWAV(path)
+	if err != nil {
+		t.Fatalf("ReadWAV() error = %v", err)
+	}
+	if buf.Samples[0] != float64(math.MaxInt16)/(1<<15) || buf.Samples[1] != -1 {
+		t.Errorf("expected samples to be clipped, got %v", buf.Samples)
+	}
+}
+
+func TestReadWAVInfo_NotWAV(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.mp3")
+	if err := os.WriteFile(path, []byte("ID3\x04\x00\x00\x00\x00\x00\x00"), 0644); err != nil {
+		t.Fatalf("failed to write test file: %v", err)
+	}
+
+	if _, err := ReadWAVInfo(path); !errors.Is(err, ErrNotWAV) {
+		t.Errorf("expected ErrNotWAV, got %v", err)
+	}
+}
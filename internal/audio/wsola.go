@@ -0,0 +1,158 @@
+package audio
+
+import (
+	"context"
+	"math"
+)
+
+// WSOLA tuning. The window is long enough to span a couple of pitch periods
+// of speech, and the search range stays below half a window so neighbouring
+// segments always overlap.
+const (
+	wsolaWindowSeconds    = 0.030
+	wsolaToleranceSeconds = 0.010
+	// wsolaCoarseStep is the stride of the first pass of the similarity search;
+	// the best coarse candidate is then refined sample by sample.
+	wsolaCoarseStep = 4
+	// wsolaCancelCheckInterval is how many segments are processed between
+	// checks for cancellation.
+	wsolaCancelCheckInterval = 64
+)
+
+// timeStretch changes the duration of interleaved samples by a factor of
+// 1/speed without changing their pitch, using waveform-similarity overlap-add
+// (WSOLA). The output holds round(frames/speed) frames. It stops early with
+// the context's error if ctx is cancelled.
+func timeStretch(ctx context.Context, samples []float64, channels, sampleRate int, speed float64) ([]float64, error) {
+	inFrames := len(samples) / channels
+	outFrames := int(math.Round(float64(inFrames) / speed))
+	if speed == 1 {
+		return append([]float64(nil), samples...), nil
+	}
+
+	window := int(float64(sampleRate)*wsolaWindowSeconds) &^ 1
+	if window < 4 {
+		window = 4
+	}
+	synthesisHop := window / 2
+	analysisHop := float64(synthesisHop) * speed
+	tolerance := int(float64(sampleRate) * wsolaToleranceSeconds)
+	if tolerance >= synthesisHop {
+		tolerance = synthesisHop - 1
+	}
+
+	// Segments are aligned on a mono mixdown and then copied for every channel,
+	// which keeps the stereo image intact.
+	guide := make([]float64, inFrames)
+	for i := range guide {
+		var sum float64
+		for c := 0; c < channels; c++ {
+			sum += samples[i*channels+c]
+		}
+		guide[i] = sum / float64(channels)
+	}
+
+	hann := make([]float64, window)
+	for i := range hann {
+		hann[i] = 0.5 - 0.5*math.Cos(2*math.Pi*float64(i)/float64(window))
+	}
+
+	out := make([]float64, (outFrames+window)*channels)
+	norm := make([]float64, outFrames+window)
+
+	prev := 0
+	for k := 0; k*synthesisHop < outFrames; k++ {
+		if k%wsolaCancelCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
+		}
+
+		pos := 0
+		if k > 0 {
+			nominal := int(math.Round(float64(k) * analysisHop))
+			pos = bestOverlap(guide, prev+synthesisHop, nominal, tolerance, synthesisHop)
+		}
+
+		outPos := k * synthesisHop
+		for i := 0; i < window; i++ {
+			src := pos + i
+			if src >= inFrames {
+				break
+			}
+			w := hann[i]
+			for c := 0; c < channels; c++ {
+				out[(outPos+i)*channels+c] += w * samples[src*channels+c]
+			}
+			norm[outPos+i] += w
+		}
+		prev = pos
+	}
+
+	out = out[:outFrames*channels]
+	for i := 0; i < outFrames; i++ {
+		if norm[i] > 1e-9 {
+			for c := 0; c < channels; c++ {
+				out[i*channels+c] /= norm[i]
+			}
+			continue
+		}
+		// Only reached where every window is zero, e.g. the very first sample.
+		src := int(math.Round(float64(i) * speed))
+		if src < inFrames {
+			copy(out[i*channels:(i+1)*channels], samples[src*channels:(src+1)*channels])
+		}
+	}
+	return out, nil
+}
+
+// bestOverlap searches around nominal for the segment start whose first
+// length frames best match the natural continuation of the previous segment,
+// using normalized cross-correlation.
+func bestOverlap(guide []float64, natural, nominal, tolerance, length int) int {
+	lo := nominal - tolerance
+	if lo < 0 {
+		lo = 0
+	}
+	hi := nominal + tolerance
+	if hi > len(guide)-1 {
+		hi = len(guide) - 1
+	}
+	if hi < lo {
+		return lo
+	}
+
+	score := func(candidate int) float64 {
+		var dot, energy float64
+		for i := 0; i < length; i++ {
+			a, b := sampleAt(guide, natural+i), sampleAt(guide, candidate+i)
+			dot += a * b
+			energy += b * b
+		}
+		return dot / math.Sqrt(energy+1e-12)
+	}
+
+	best, bestScore := lo, math.Inf(-1)
+	for candidate := lo; candidate <= hi; candidate += wsolaCoarseStep {
+		if s := score(candidate); s > bestScore {
+			best, bestScore = candidate, s
+		}
+	}
+	coarse := best
+	for candidate := coarse - wsolaCoarseStep + 1; candidate < coarse+wsolaCoarseStep; candidate++ {
+		if candidate < lo || candidate > hi || candidate == coarse {
+			continue
+		}
+		if s := score(candidate); s > bestScore {
+			best, bestScore = candidate, s
+		}
+	}
+	return best
+}
+
+func sampleAt(samples []float64, i int) float64 {
+	if i < 0 || i >= len(samples) {
+		return 0
+	}
+	return samples[i]
+}
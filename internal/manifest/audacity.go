@@ -0,0 +1,155 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// audacityLabelSeparator splits an Audacity label into speaker and clip path.
+const audacityLabelSeparator = "|"
+
+// parseAudacity reads an Audacity label track export: one tab-separated
+// "start end label" line per label. Labels of the form "SPEAKER|path" give the
+// speaker and clip; a label without a separator is the clip path alone.
+// Labels without a path get one from opts.PathTemplate, which can use {label}
+// besides the common fields. Spectral selection lines are ignored.
+func parseAudacity(data []byte, opts Options) ([]ManifestEntry, error) {
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = DefaultPathTemplate
+	}
+
+	var entries []ManifestEntry
+	probs := newProblems(opts)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, `\`) {
+			continue
+		}
+
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 2 {
+			if !probs.add(errorAt(lineNo, 1, "expected tab-separated start, end and label, got %q", line)) {
+				break
+			}
+			continue
+		}
+		start, err := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		if err != nil {
+			if !probs.add(errorAt(lineNo, 1, "invalid start time %q", fields[0])) {
+				break
+			}
+			continue
+		}
+		end, err := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		if err != nil {
+			if !probs.add(errorAt(lineNo, utf8.RuneCountInString(fields[0])+2, "invalid end time %q", fields[1])) {
+				break
+			}
+			continue
+		}
+
+		var label, speaker, path string
+		if len(fields) == 3 {
+			label = strings.TrimSpace(fields[2])
+		}
+		path = label
+		if s, p, ok := strings.Cut(label, audacityLabelSeparator); ok {
+			speaker, path = strings.TrimSpace(s), strings.TrimSpace(p)
+		}
+		if path == "" {
+			path, err = expandTemplate(tmpl, templateFields{
+				"index":   len(entries),
+				"number":  len(entries) + 1,
+				"speaker": speaker,
+				"start":   start,
+				"end":     end,
+				"label":   label,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		entries = append(entries, ManifestEntry{
+			StartTime: start,
+			EndTime:   end,
+			Speaker:   speaker,
+			FilePath:  path,
+		})
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return entries, nil
+}
+
+// writeAudacity writes entries as an Audacity label track, with times to at
+// least six decimal places as Audacity itself exports them, and more if they
+// need it.
+func writeAudacity(w io.Writer, entries []ManifestEntry, opts Options) error {
+	for i, entry := range entries {
+		if !audacitySpeakerWritable(entry.Speaker) {
+			return fmt.Errorf("entry %d: speaker %q can't be written in an Audacity label", i+1, entry.Speaker)
+		}
+		if !audacityPathWritable(entry.FilePath) {
+			return fmt.Errorf("entry %d: path %q can't be written in an Audacity label", i+1, entry.FilePath)
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	for _, entry := range entries {
+		label := entry.FilePath
+		if entry.Speaker != "" || strings.Contains(entry.FilePath, audacityLabelSeparator) {
+			label = entry.Speaker + audacityLabelSeparator + entry.FilePath
+		}
+		fmt.Fprintf(writer, "%s\t%s\t%s\n", formatDecimal(entry.StartTime, 6), formatDecimal(entry.EndTime, 6), label)
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	return nil
+}
+
+// audacitySpeakerWritable reports whether speaker reads back unchanged from
+// an Audacity label: it can't hold the separator, a tab or a line break, nor
+// start or end with a space, which parseAudacity trims.
+func audacitySpeakerWritable(speaker string) bool {
+	return !strings.ContainsAny(speaker, audacityLabelSeparator+"\t\r\n") && strings.TrimSpace(speaker) == speaker
+}
+
+// audacityPathWritable reports whether path reads back unchanged from an
+// Audacity label: it can't be empty, hold a tab or a line break, nor start or
+// end with a space.
+func audacityPathWritable(path string) bool {
+	return path != "" && !strings.ContainsAny(path, "\t\r\n") && strings.TrimSpace(path) == path
+}
+
+// looksLikeAudacity reports whether the first line of data starts with two
+// tab-separated numbers.
+func looksLikeAudacity(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		fields := strings.SplitN(line, "\t", 3)
+		if len(fields) < 2 {
+			return false
+		}
+		_, startErr := strconv.ParseFloat(strings.TrimSpace(fields[0]), 64)
+		_, endErr := strconv.ParseFloat(strings.TrimSpace(fields[1]), 64)
+		return startErr == nil && endErr == nil
+	}
+	return false
+}
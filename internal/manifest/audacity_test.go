@@ -0,0 +1,116 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_Audacity(t *testing.T) {
+	content := "0.500000\t2.750000\tSPEAKER_00|clips/000.wav\r\n" +
+		"\\\t100.000000\t2000.000000\r\n" +
+		"3.000000\t4.000000\tclips/my take 1.wav\n" +
+		"5.000000\t5.500000\tSPEAKER_01|\n" +
+		"6.000000\t6.250000\n"
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "labels.txt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "gen/{index}_{speaker}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{StartTime: 0.5, EndTime: 2.75, Speaker: "SPEAKER_00", FilePath: filepath.Join(tmpDir, "clips/000.wav")},
+		{StartTime: 3.0, EndTime: 4.0, FilePath: filepath.Join(tmpDir, "clips/my take 1.wav")},
+		{StartTime: 5.0, EndTime: 5.5, Speaker: "SPEAKER_01", FilePath: filepath.Join(tmpDir, "gen/2_SPEAKER_01.wav")},
+		{StartTime: 6.0, EndTime: 6.25, FilePath: filepath.Join(tmpDir, "gen/3_.wav")},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestWrite_Audacity_RoundTrip(t *testing.T) {
+	entries := []ManifestEntry{
+		{StartTime: 0.0, EndTime: 5.0, Speaker: "SPEAKER_00", FilePath: "/path/to/audio/000.wav"},
+		{StartTime: 5.7, EndTime: 8.4, FilePath: "/path/to/my clips/001.wav"},
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "labels.txt")
+	if err := WriteWithOptions(manifestPath, entries, Options{Format: FormatAudacity}); err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+
+	m, err := Load(manifestPath, Options{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	if m.Format != FormatAudacity {
+		t.Errorf("expected the label track to be detected as %q, got %q", FormatAudacity, m.Format)
+	}
+	if !reflect.DeepEqual(m.Entries, entries) {
+		t.Errorf("expected %+v, got %+v", entries, m.Entries)
+	}
+}
+
+func TestWrite_AudacityUnwritableEntries(t *testing.T) {
+	testCases := map[string]ManifestEntry{
+		"speaker with the separator":  {StartTime: 0, EndTime: 1, Speaker: "A|B", FilePath: "x.wav"},
+		"speaker with a tab":          {StartTime: 0, EndTime: 1, Speaker: "A\tB", FilePath: "x.wav"},
+		"speaker with a newline":      {StartTime: 0, EndTime: 1, Speaker: "A\nB", FilePath: "x.wav"},
+		"speaker with a space around": {StartTime: 0, EndTime: 1, Speaker: " A", FilePath: "x.wav"},
+		"path with a tab":             {StartTime: 0, EndTime: 1, Speaker: "A", FilePath: "x\t.wav"},
+		"path with a newline":         {StartTime: 0, EndTime: 1, Speaker: "A", FilePath: "x\n.wav"},
+		"path with a leading space":   {StartTime: 0, EndTime: 1, FilePath: " x.wav"},
+		"path with a trailing space":  {StartTime: 0, EndTime: 1, Speaker: "A", FilePath: "x.wav "},
+		"empty path":                  {StartTime: 0, EndTime: 1, Speaker: "A"},
+	}
+
+	for name, entry := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := &Manifest{Entries: []ManifestEntry{entry}}
+			if losses := Losses(m, Options{Format: FormatAudacity}); len(losses) != 1 {
+				t.Errorf("expected one loss, got %q", losses)
+			}
+			var buf strings.Builder
+			if err := Encode(&buf, m, Options{Format: FormatAudacity}); err == nil || buf.Len() > 0 {
+				t.Errorf("expected an error and no output, got %v and %q", err, buf.String())
+			}
+		})
+	}
+
+	// A separator in the path of an entry without a speaker is kept.
+	entries := []ManifestEntry{{StartTime: 0, EndTime: 1, FilePath: "a|b.wav"}}
+	var buf strings.Builder
+	if err := Encode(&buf, &Manifest{Entries: entries}, Options{Format: FormatAudacity}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got, err := parseAudacity([]byte(buf.String()), Options{})
+	if err != nil || !reflect.DeepEqual(got, entries) {
+		t.Errorf("expected %+v to read back, got %+v, %v", entries, got, err)
+	}
+}
+
+func TestParse_Audacity_InvalidTime(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "labels.txt")
+	content := "0.5\t1.0\tSPEAKER_00|a.wav\n1.0\tabc\tSPEAKER_00|b.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for an invalid end time, but got nil")
+	}
+}
@@ -0,0 +1,148 @@
+package manifest
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+)
+
+// holds lists the optional parts of a manifest that a writable format keeps.
+// Start and end times, speakers and paths are kept by every format, except
+// for the few values the text, WebVTT and Audacity formats can't write (see
+// textSpeakerWritable, vttLineWritable and audacitySpeakerWritable).
+type holds struct {
+	id, text, gain, words, extra bool
+	// comments are the comments before entries; headerComments are those
+	// before directives, and trailingComments those after the last entry.
+	comments, directives, headerComments, trailingComments bool
+}
+
+var formatHolds = map[Format]holds{
+	FormatText:     {comments: true, directives: true, headerComments: true, trailingComments: true},
+	FormatVTT:      {id: true, text: true},
+	FormatJSON:     {id: true, text: true, gain: true, words: true, extra: true, comments: true, directives: true, trailingComments: true},
+	FormatJSONL:    {id: true, text: true, gain: true, words: true, extra: true, comments: true, directives: true},
+	FormatAudacity: {},
+	FormatCSV:      {id: true, text: true, gain: true, extra: true},
+	FormatTSV:      {id: true, text: true, gain: true, extra: true},
+}
+
+// Losses describes what of m would be lost by writing it in opts.Format,
+// e.g. "the text of 12 entries". Times are lost if the format rounds them,
+// as WebVTT does to milliseconds. It returns nil if m can be written in full.
+func Losses(m *Manifest, opts Options) []string {
+	format := opts.Format
+	if format == "" {
+		format = FormatText
+	}
+	h := formatHolds[format]
+
+	var speakers, paths, id, text, gain, words, comments, times int
+	extraNames := make(map[string]bool)
+	extra := 0
+	round := timeRounding(format, opts)
+	count := func(n *int, lost bool) {
+		if lost {
+			*n++
+		}
+	}
+	for _, entry := range m.Entries {
+		switch format {
+		case FormatText:
+			count(&speakers, !textSpeakerWritable(entry.Speaker))
+			count(&paths, !textPathWritable(entry.FilePath))
+		case FormatVTT:
+			count(&speakers, !vttSpeakerWritable(entry.Speaker))
+			count(&paths, !vttLineWritable(entry.FilePath))
+		case FormatAudacity:
+			count(&speakers, !audacitySpeakerWritable(entry.Speaker))
+			count(&paths, !audacityPathWritable(entry.FilePath))
+		}
+		count(&id, (!h.id && entry.ID != "") || (format == FormatVTT && !vttLineWritable(entry.ID)))
+		count(&text, !h.text && entry.Text != "")
+		count(&gain, !h.gain && entry.Gain != 0)
+		count(&words, !h.words && len(entry.Words) > 0)
+		count(&comments, !h.comments && len(entry.Comments) > 0)
+		count(&times, round != nil && (round(entry.StartTime) != entry.StartTime || round(entry.EndTime) != entry.EndTime))
+		if !h.extra && len(entry.Extra) > 0 {
+			extra++
+			for name := range entry.Extra {
+				extraNames[name] = true
+			}
+		}
+	}
+
+	var losses []string
+	add := func(n int, what string) {
+		if n > 0 {
+			losses = append(losses, fmt.Sprintf("%s of %s", what, plural(n, "entry")))
+		}
+	}
+	add(speakers, "the speakers")
+	add(paths, "the paths")
+	add(id, "the IDs")
+	add(text, "the text")
+	add(gain, "the gain")
+	add(words, "the word timings")
+	if extra > 0 {
+		names := make([]string, 0, len(extraNames))
+		for name := range extraNames {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		add(extra, fmt.Sprintf("the extra fields (%s)", strings.Join(names, ", ")))
+	}
+	add(comments, "the comments")
+	add(times, "the exact times")
+
+	headerComments := 0
+	for _, d := range m.Directives {
+		if len(d.Comments) > 0 {
+			headerComments++
+		}
+	}
+	switch {
+	case !h.directives && len(m.Directives) > 0:
+		losses = append(losses, plural(len(m.Directives), "directive"))
+	case !h.headerComments && headerComments > 0:
+		losses = append(losses, "the comments before directives")
+	}
+	if !h.trailingComments && len(m.Comments) > 0 {
+		losses = append(losses, "the comments after the last entry")
+	}
+	return losses
+}
+
+// timeRounding returns how format rounds times when written with opts, or
+// nil if it writes them exactly.
+func timeRounding(format Format, opts Options) func(float64) float64 {
+	ms := func(seconds float64) float64 {
+		return math.Round(seconds*1000) / 1000
+	}
+	switch {
+	case format == FormatVTT:
+		return ms
+	case format == FormatText && (opts.TimeFormat == TimeMilliseconds || opts.TimeFormat == TimeClock):
+		return ms
+	case format == FormatText && opts.TimeFormat == TimeTimecode && !opts.FrameRate.IsZero():
+		return func(seconds float64) float64 {
+			if _, ok := opts.FrameRate.FrameAt(seconds); ok {
+				return seconds
+			}
+			return opts.FrameRate.Snap(seconds)
+		}
+	}
+	return nil
+}
+
+// plural counts n of noun, e.g. "1 entry" or "3 entries".
+func plural(n int, noun string) string {
+	switch {
+	case n == 1:
+		return "1 " + noun
+	case strings.HasSuffix(noun, "y"):
+		return fmt.Sprintf("%d %sies", n, strings.TrimSuffix(noun, "y"))
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
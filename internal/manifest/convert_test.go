@@ -0,0 +1,81 @@
+package manifest
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLosses(t *testing.T) {
+	m := &Manifest{
+		Directives: []Directive{{Key: "bed", Value: "music.wav", Comments: []string{"# Mix"}}},
+		Entries: []ManifestEntry{
+			{StartTime: 1, EndTime: 2.0004, Speaker: "A", FilePath: "a.wav", ID: "1", Text: "Hi.", Comments: []string{"# Act 1"}},
+			{StartTime: 3, EndTime: 4, FilePath: "b.wav", Text: "Bye.", Gain: -3, Extra: map[string]string{"take": "2", "notes": "slow"}},
+			{StartTime: 5, EndTime: 6, FilePath: "c.wav", Words: []Word{{Text: "ok", Start: 5, End: 6}}},
+		},
+		Comments: []string{"# end"},
+	}
+
+	testCases := []struct {
+		opts     Options
+		expected []string
+	}{
+		{Options{Format: FormatText}, []string{
+			"the IDs of 1 entry",
+			"the text of 2 entries",
+			"the gain of 1 entry",
+			"the word timings of 1 entry",
+			"the extra fields (notes, take) of 1 entry",
+		}},
+		{Options{Format: FormatText, TimeFormat: TimeMilliseconds}, []string{
+			"the IDs of 1 entry",
+			"the text of 2 entries",
+			"the gain of 1 entry",
+			"the word timings of 1 entry",
+			"the extra fields (notes, take) of 1 entry",
+			"the exact times of 1 entry",
+		}},
+		{Options{Format: FormatJSON}, []string{"the comments before directives"}},
+		{Options{Format: FormatJSONL}, []string{"the comments before directives", "the comments after the last entry"}},
+		{Options{Format: FormatCSV}, []string{
+			"the word timings of 1 entry",
+			"the comments of 1 entry",
+			"1 directive",
+			"the comments after the last entry",
+		}},
+		{Options{Format: FormatVTT}, []string{
+			"the gain of 1 entry",
+			"the word timings of 1 entry",
+			"the extra fields (notes, take) of 1 entry",
+			"the comments of 1 entry",
+			"the exact times of 1 entry",
+			"1 directive",
+			"the comments after the last entry",
+		}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.opts.Format)+string(tc.opts.TimeFormat), func(t *testing.T) {
+			if got := Losses(m, tc.opts); !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %q, got %q", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLosses_Lossless(t *testing.T) {
+	m := &Manifest{Entries: []ManifestEntry{{StartTime: 1.5, EndTime: 2, Speaker: "A", FilePath: "a.wav"}}}
+	for format := range writers {
+		if got := Losses(m, Options{Format: format}); got != nil {
+			t.Errorf("%s: expected no losses, got %q", format, got)
+		}
+	}
+}
+
+func TestFormatHolds_CoversWriters(t *testing.T) {
+	for format := range writers {
+		if _, ok := formatHolds[format]; !ok {
+			t.Errorf("formatHolds is missing writable format %s", format)
+		}
+	}
+}
@@ -0,0 +1,319 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// csvFields are the entry fields that can be mapped to table columns, in the
+// order they are written.
+var csvFields = []string{"start", "end", "speaker", "path", "id", "text", "gain"}
+
+// csvRequired are the fields a table must have columns for.
+var csvRequired = []string{"start", "end", "path"}
+
+func parseCSV(data []byte, opts Options) (*Manifest, error) {
+	return parseTable(data, ',', opts)
+}
+
+func parseTSV(data []byte, opts Options) (*Manifest, error) {
+	return parseTable(data, '\t', opts)
+}
+
+func writeCSV(w io.Writer, m *Manifest, opts Options) error {
+	return writeTable(w, m, ',', opts)
+}
+
+func writeTSV(w io.Writer, m *Manifest, opts Options) error {
+	return writeTable(w, m, '\t', opts)
+}
+
+// columnHeaders returns the header name of every entry field, applying the
+// mapping in columns.
+func columnHeaders(columns map[string]string) (map[string]string, error) {
+	headers := make(map[string]string, len(csvFields))
+	for _, field := range csvFields {
+		headers[field] = field
+	}
+	for field, header := range columns {
+		field = strings.ToLower(strings.TrimSpace(field))
+		if _, ok := headers[field]; !ok {
+			return nil, fmt.Errorf("unknown field %q in column mapping (expected one of %s)", field, strings.Join(csvFields, ", "))
+		}
+		headers[field] = strings.TrimSpace(header)
+	}
+	return headers, nil
+}
+
+// parseTable reads a delimited table whose first row names the columns.
+// Headers are matched case-insensitively; columns that don't map to an entry
+// field are kept in Extra. Blank rows are skipped.
+func parseTable(data []byte, comma rune, opts Options) (*Manifest, error) {
+	headers, err := columnHeaders(opts.Columns)
+	if err != nil {
+		return nil, err
+	}
+	fieldByHeader := make(map[string]string, len(headers))
+	for field, header := range headers {
+		fieldByHeader[strings.ToLower(header)] = field
+	}
+
+	r := csv.NewReader(bytes.NewReader(data))
+	r.Comma = comma
+	// Tab-separated exports rarely quote their fields.
+	r.LazyQuotes = comma == '\t'
+
+	header, err := r.Read()
+	if err == io.EOF {
+		return nil, errorAt(1, 1, "missing header row")
+	}
+	if err != nil {
+		return nil, csvError(err)
+	}
+
+	m := &Manifest{}
+	columns := make(map[string]int)
+	extra := make(map[int]string)
+	for i, name := range header {
+		name = strings.TrimSpace(name)
+		m.Columns = append(m.Columns, name)
+		field, ok := fieldByHeader[strings.ToLower(name)]
+		if _, seen := columns[field]; ok && !seen {
+			columns[field] = i
+			continue
+		}
+		extra[i] = name
+	}
+	for _, field := range csvRequired {
+		if _, ok := columns[field]; !ok {
+			return nil, errorAt(1, 1, "missing %q column for the %s field", headers[field], field)
+		}
+	}
+
+	probs := newProblems(opts)
+	for {
+		record, err := r.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			if !probs.add(csvError(err)) {
+				break
+			}
+			continue
+		}
+		if isBlankRecord(record) {
+			continue
+		}
+
+		entry, entryErr := tableEntry(r, record, columns, headers)
+		if entryErr != nil {
+			if !probs.add(entryErr) {
+				break
+			}
+			continue
+		}
+		for i, name := range extra {
+			if entry.Extra == nil {
+				entry.Extra = make(map[string]string, len(extra))
+			}
+			entry.Extra[name] = record[i]
+		}
+		m.Entries = append(m.Entries, entry)
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// csvError converts an error from the CSV reader to a ParseError.
+func csvError(err error) *ParseError {
+	var csvErr *csv.ParseError
+	if errors.As(err, &csvErr) {
+		return errorAt(csvErr.Line, csvErr.Column, "%v", csvErr.Err)
+	}
+	return errorAt(0, 0, "%v", err)
+}
+
+// tableEntry converts the record just read by r to an entry.
+func tableEntry(r *csv.Reader, record []string, columns map[string]int, headers map[string]string) (ManifestEntry, *ParseError) {
+	value := func(field string) string {
+		if i, ok := columns[field]; ok {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+	errorIn := func(field, format string, args ...any) *ParseError {
+		line, column := r.FieldPos(columns[field])
+		return errorAt(line, column, format, args...)
+	}
+	number := func(field string) (float64, *ParseError) {
+		s := value(field)
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, errorIn(field, "invalid %s %q in column %q", field, s, headers[field])
+		}
+		return v, nil
+	}
+
+	var entry ManifestEntry
+	var err *ParseError
+	if entry.StartTime, err = number("start"); err != nil {
+		return ManifestEntry{}, err
+	}
+	if entry.EndTime, err = number("end"); err != nil {
+		return ManifestEntry{}, err
+	}
+	if entry.FilePath = value("path"); entry.FilePath == "" {
+		return ManifestEntry{}, errorIn("path", "empty path in column %q", headers["path"])
+	}
+	entry.Speaker = value("speaker")
+	entry.ID = value("id")
+	if i, ok := columns["text"]; ok {
+		// Text keeps its surrounding whitespace.
+		entry.Text = record[i]
+	}
+	if value("gain") != "" {
+		if entry.Gain, err = number("gain"); err != nil {
+			return ManifestEntry{}, err
+		}
+	}
+	return entry, nil
+}
+
+func isBlankRecord(record []string) bool {
+	for _, field := range record {
+		if strings.TrimSpace(field) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// tableColumn is a column written by writeTable.
+type tableColumn struct {
+	header string
+	// field is the entry field held in the column, or "" for an Extra field.
+	field string
+}
+
+// writeTable writes entries as a delimited table with a header row. The
+// start, end and path columns are always written, and so is speaker unless
+// m.Columns lacks it; id, text and gain only if an entry uses them or
+// m.Columns names them. Columns named in
+// m.Columns come first, in that order; the remaining fields follow, and then
+// the remaining extra fields, sorted by name.
+func writeTable(w io.Writer, m *Manifest, comma rune, opts Options) error {
+	headers, err := columnHeaders(opts.Columns)
+	if err != nil {
+		return err
+	}
+	fieldByHeader := make(map[string]string, len(headers))
+	for field, header := range headers {
+		fieldByHeader[strings.ToLower(header)] = field
+	}
+	entries := m.Entries
+
+	used := map[string]bool{"start": true, "end": true, "speaker": len(m.Columns) == 0, "path": true}
+	extraSet := make(map[string]bool)
+	for _, entry := range entries {
+		used["speaker"] = used["speaker"] || entry.Speaker != ""
+		used["id"] = used["id"] || entry.ID != ""
+		used["text"] = used["text"] || entry.Text != ""
+		used["gain"] = used["gain"] || entry.Gain != 0
+		for name := range entry.Extra {
+			extraSet[name] = true
+		}
+	}
+	var columns []tableColumn
+	added := make(map[tableColumn]bool)
+	add := func(c tableColumn) {
+		key := c
+		if c.field != "" {
+			key.header = ""
+		}
+		if !added[key] {
+			added[key] = true
+			columns = append(columns, c)
+		}
+	}
+	for _, name := range m.Columns {
+		if field, ok := fieldByHeader[strings.ToLower(name)]; ok {
+			add(tableColumn{header: name, field: field})
+		} else if extraSet[name] {
+			add(tableColumn{header: name})
+		}
+	}
+	for _, field := range csvFields {
+		if used[field] {
+			add(tableColumn{header: headers[field], field: field})
+		}
+	}
+	extra := make([]string, 0, len(extraSet))
+	for name := range extraSet {
+		extra = append(extra, name)
+	}
+	sort.Strings(extra)
+	for _, name := range extra {
+		add(tableColumn{header: name})
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.header
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(header); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	record := make([]string, len(header))
+	for _, entry := range entries {
+		for i, c := range columns {
+			if c.field != "" {
+				record[i] = tableValue(entry, c.field)
+			} else {
+				record[i] = entry.Extra[c.header]
+			}
+		}
+		if err := cw.Write(record); err != nil {
+			return fmt.Errorf("failed to write to manifest: %w", err)
+		}
+	}
+	cw.Flush()
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	return nil
+}
+
+func tableValue(entry ManifestEntry, field string) string {
+	switch field {
+	case "start":
+		return strconv.FormatFloat(entry.StartTime, 'f', -1, 64)
+	case "end":
+		return strconv.FormatFloat(entry.EndTime, 'f', -1, 64)
+	case "speaker":
+		return entry.Speaker
+	case "path":
+		return entry.FilePath
+	case "id":
+		return entry.ID
+	case "text":
+		return entry.Text
+	case "gain":
+		if entry.Gain == 0 {
+			return ""
+		}
+		return strconv.FormatFloat(entry.Gain, 'f', -1, 64)
+	}
+	return ""
+}
@@ -0,0 +1,87 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_CSV_ColumnMapping(t *testing.T) {
+	content := "Scene,In,Out,Character,File,Notes\n" +
+		"1,0.5,2.75,ALICE,clips/000.wav,\"Warm, slow\"\n" +
+		",,,,,\n" +
+		"2,3,4.125,,clips/001.wav,\n"
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "script.csv")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	opts := Options{Columns: map[string]string{"start": "In", "end": "out", "speaker": "Character", "path": "File"}}
+	entries, err := ParseWithOptions(manifestPath, opts)
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{StartTime: 0.5, EndTime: 2.75, Speaker: "ALICE", FilePath: filepath.Join(tmpDir, "clips/000.wav"), Extra: map[string]string{"Scene": "1", "Notes": "Warm, slow"}},
+		{StartTime: 3, EndTime: 4.125, FilePath: filepath.Join(tmpDir, "clips/001.wav"), Extra: map[string]string{"Scene": "2", "Notes": ""}},
+	}
+	if !reflect.DeepEqual(entries, expected) {
+		t.Errorf("expected %+v, got %+v", expected, entries)
+	}
+}
+
+func TestWrite_Table_RoundTrip(t *testing.T) {
+	entries := []ManifestEntry{
+		{ID: "a", StartTime: 0.1, EndTime: 5.000001, Speaker: "SPEAKER_00", FilePath: "/audio/000.wav", Text: " Hello,\n\"world\"", Gain: -1.5, Extra: map[string]string{"take": "3", "note": "ok"}},
+		{StartTime: 5.7, EndTime: 8.4, FilePath: "/audio/my clips/001.wav", Extra: map[string]string{"take": "", "note": "tab\there"}},
+	}
+	opts := Options{Columns: map[string]string{"start": "Start Time", "path": "Clip"}}
+
+	for _, name := range []string{"script.csv", "script.tsv"} {
+		t.Run(name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), name)
+			if err := WriteWithOptions(manifestPath, entries, opts); err != nil {
+				t.Fatalf("WriteWithOptions() error = %v", err)
+			}
+
+			parsed, err := ParseWithOptions(manifestPath, opts)
+			if err != nil {
+				t.Fatalf("ParseWithOptions() error = %v", err)
+			}
+			if !reflect.DeepEqual(parsed, entries) {
+				t.Errorf("expected %+v, got %+v", entries, parsed)
+			}
+		})
+	}
+}
+
+func TestParse_Table_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		columns map[string]string
+	}{
+		{"empty", "", nil},
+		{"missing path column", "start,end,speaker\n0,1,A\n", nil},
+		{"invalid start", "start,end,path\nabc,1,a.wav\n", nil},
+		{"empty path", "start,end,path\n0,1,\n", nil},
+		{"ragged row", "start,end,path\n0,1,a.wav,extra\n", nil},
+		{"unknown field", "start,end,path\n0,1,a.wav\n", map[string]string{"duration": "Length"}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "script.csv")
+			if err := os.WriteFile(manifestPath, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			if _, err := ParseWithOptions(manifestPath, Options{Columns: tc.columns}); err == nil {
+				t.Error("expected an error, but got nil")
+			}
+		})
+	}
+}
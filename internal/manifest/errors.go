@@ -0,0 +1,124 @@
+package manifest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// ParseError is a problem at a position in a manifest file. Line and Column
+// are one-based, and zero when not known. It prints as "file:line:col: reason",
+// which editors can jump to.
+type ParseError struct {
+	File   string
+	Line   int
+	Column int
+	Reason string
+}
+
+func (e *ParseError) Error() string {
+	var pos []string
+	if e.File != "" {
+		pos = append(pos, e.File)
+	}
+	if e.Line > 0 {
+		pos = append(pos, strconv.Itoa(e.Line))
+		if e.Column > 0 {
+			pos = append(pos, strconv.Itoa(e.Column))
+		}
+	}
+	if len(pos) == 0 {
+		return e.Reason
+	}
+	return strings.Join(pos, ":") + ": " + e.Reason
+}
+
+// ErrorList holds every problem found in a manifest read with
+// Options.CollectErrors. It prints one problem per line, and errors.As finds
+// the ParseErrors in it.
+type ErrorList []*ParseError
+
+func (l ErrorList) Error() string {
+	lines := make([]string, len(l))
+	for i, e := range l {
+		lines[i] = e.Error()
+	}
+	return strings.Join(lines, "\n")
+}
+
+func (l ErrorList) Unwrap() []error {
+	errs := make([]error, len(l))
+	for i, e := range l {
+		errs[i] = e
+	}
+	return errs
+}
+
+// errorAt returns a ParseError at the given line and column of the file
+// being read.
+func errorAt(line, column int, format string, args ...any) *ParseError {
+	return &ParseError{Line: line, Column: column, Reason: fmt.Sprintf(format, args...)}
+}
+
+// problems gathers the errors a reader finds. Unless it collects all of them,
+// the first one ends the read.
+type problems struct {
+	collect bool
+	list    ErrorList
+}
+
+func newProblems(opts Options) *problems {
+	return &problems{collect: opts.CollectErrors}
+}
+
+// add records a problem and reports whether the reader should carry on.
+func (p *problems) add(err *ParseError) bool {
+	p.list = append(p.list, err)
+	return p.collect
+}
+
+// err returns the problems found, if any: the first one, or all of them
+// when collecting.
+func (p *problems) err() error {
+	switch {
+	case len(p.list) == 0:
+		return nil
+	case p.collect:
+		return p.list
+	default:
+		return p.list[0]
+	}
+}
+
+// withFile attributes the parse errors in err to file. Other errors become a
+// ParseError for the whole file.
+func withFile(file string, err error) error {
+	var list ErrorList
+	if errors.As(err, &list) {
+		for _, e := range list {
+			e.File = file
+		}
+		return list
+	}
+	var pe *ParseError
+	if errors.As(err, &pe) {
+		pe.File = file
+		return pe
+	}
+	return &ParseError{File: file, Reason: err.Error()}
+}
+
+// offsetPosition returns the one-based line and column of a byte offset in
+// data.
+func offsetPosition(data []byte, offset int64) (line, column int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line = bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	return line, utf8.RuneCount(before[lineStart:]) + 1
+}
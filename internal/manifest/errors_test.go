@@ -0,0 +1,125 @@
+package manifest
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParseError_Error(t *testing.T) {
+	testCases := []struct {
+		err      *ParseError
+		expected string
+	}{
+		{&ParseError{File: "m.txt", Line: 3, Column: 14, Reason: "expected \"]\""}, `m.txt:3:14: expected "]"`},
+		{&ParseError{File: "m.txt", Line: 3, Reason: "bad"}, "m.txt:3: bad"},
+		{&ParseError{File: "m.json", Reason: "missing \"entries\""}, `m.json: missing "entries"`},
+		{&ParseError{Line: 2, Column: 1, Reason: "bad"}, "2:1: bad"},
+	}
+	for _, tc := range testCases {
+		if got := tc.err.Error(); got != tc.expected {
+			t.Errorf("expected %q, got %q", tc.expected, got)
+		}
+	}
+}
+
+func TestLoad_ParseError(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+	content := "[0.0s–1.0s] (A) a.wav\n  [1.0s–2.0s (A) b.wav\n[oops] (A) c.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	_, err := Load(manifestPath, Options{})
+	var pe *ParseError
+	if !errors.As(err, &pe) {
+		t.Fatalf("expected a *ParseError, got %v", err)
+	}
+	expected := ParseError{File: manifestPath, Line: 2, Column: 14, Reason: `expected "]"`}
+	if *pe != expected {
+		t.Errorf("expected %+v, got %+v", expected, *pe)
+	}
+}
+
+func TestLoad_CollectErrors(t *testing.T) {
+	testCases := []struct {
+		name     string
+		content  string
+		expected []ParseError
+	}{
+		{
+			name:    "manifest.txt",
+			content: "[0.0s–1.0s] (A) a.wav\n[1.0s–2.0s (A) b.wav\n[2.0s–3.0s] (A) c.wav\n#! late=1\n[x] (A) d.wav\n",
+			expected: []ParseError{
+				{Line: 2, Column: 12, Reason: `expected "]"`},
+				{Line: 4, Column: 1, Reason: "directives must come before the first entry"},
+				{Line: 5, Column: 2, Reason: `invalid time "x" (` + textTimeForms + ")"},
+			},
+		},
+		{
+			name:    "script.csv",
+			content: "start,end,path\n0,1,a.wav\nx,2,b.wav\n2,3,\n",
+			expected: []ParseError{
+				{Line: 3, Column: 1, Reason: `invalid start "x" in column "start"`},
+				{Line: 4, Column: 5, Reason: `empty path in column "path"`},
+			},
+		},
+		{
+			name:    "manifest.json",
+			content: "{\"version\": 1, \"entries\": [\n  {\"start\": 0, \"end\": 1, \"path\": \"a.wav\"},\n  {\"end\": 2, \"path\": \"b.wav\"},\n  {\"start\": 2, \"end\": 3, \"path\": \"c.wav\"}, {\"start\": 3, \"end\": 4}\n]}\n",
+			expected: []ParseError{
+				{Line: 3, Column: 3, Reason: `entry 2: missing "start"`},
+				{Line: 4, Column: 44, Reason: `entry 4: missing "path"`},
+			},
+		},
+		{
+			name:    "episode.srt",
+			content: "1\n00:00:01,000 --> 00:00:02,000\nHi.\n\nhello\nthere\n\n3\n00:00:03,000 --> 00:00:04,000\nBye.\n\n4\n5\n",
+			expected: []ParseError{
+				{Line: 5, Column: 1, Reason: `expected a cue number or timing line, got "hello"`},
+				{Line: 13, Column: 1, Reason: `expected a cue number or timing line, got "5"`},
+			},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), tc.name)
+			if err := os.WriteFile(manifestPath, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			_, err := Load(manifestPath, Options{CollectErrors: true})
+			var list ErrorList
+			if !errors.As(err, &list) {
+				t.Fatalf("expected an ErrorList, got %v", err)
+			}
+			var got []ParseError
+			for _, e := range list {
+				if e.File != manifestPath {
+					t.Errorf("expected file %s, got %s", manifestPath, e.File)
+				}
+				e.File = ""
+				got = append(got, *e)
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected %+v, got %+v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestLoad_JSONSyntaxErrorPosition(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(manifestPath, []byte("{\"version\": 1,\n  \"entries\": [,]}\n"), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	_, err := Parse(manifestPath)
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.Line != 2 {
+		t.Errorf("expected a ParseError on line 2, got %v", err)
+	}
+}
@@ -0,0 +1,421 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// SchemaVersion is the version of the JSON manifest schema written by this
+// package. Readers accept any version up to and including it.
+//
+// Version 1 documents look like this; "directives", "comments", and the
+// entry fields "id", "text", "gain", "words", "extra" and "comments" are
+// optional:
+//
+//	{
+//	  "version": 1,
+//	  "directives": {"sample_rate": "48000"},
+//	  "entries": [
+//	    {"start": 0.0, "end": 5.0, "speaker": "SPEAKER_00", "path": "clips/000.wav",
+//	     "id": "intro", "text": "Hello there.", "gain": -3.0,
+//	     "words": [{"word": "Hello", "start": 0.1, "end": 0.4, "score": 0.98}],
+//	     "extra": {"take": "3"}, "comments": ["# Act 1"]}
+//	  ],
+//	  "comments": ["# end of reel"]
+//	}
+//
+// JSON Lines manifests hold one entry object per line, optionally preceded by
+// a {"version": 1} header line, which may also hold "directives".
+const SchemaVersion = 1
+
+// jsonEntry is the JSON representation of a ManifestEntry. Pointers tell
+// missing required fields apart from zero values.
+type jsonEntry struct {
+	ID       string            `json:"id,omitempty"`
+	Start    *float64          `json:"start"`
+	End      *float64          `json:"end"`
+	Speaker  string            `json:"speaker"`
+	Path     string            `json:"path"`
+	Text     string            `json:"text,omitempty"`
+	Gain     float64           `json:"gain,omitempty"`
+	Words    []jsonWord        `json:"words,omitempty"`
+	Extra    map[string]string `json:"extra,omitempty"`
+	Comments []string          `json:"comments,omitempty"`
+}
+
+type jsonWord struct {
+	Word  string  `json:"word"`
+	Start float64 `json:"start"`
+	End   float64 `json:"end"`
+	Score float64 `json:"score,omitempty"`
+}
+
+type jsonDocument struct {
+	Version    *int           `json:"version"`
+	Directives jsonDirectives `json:"directives,omitempty"`
+	Entries    []jsonEntry    `json:"entries"`
+	Comments   []string       `json:"comments,omitempty"`
+}
+
+// jsonLinesHeader is the optional first line of a JSON Lines manifest.
+type jsonLinesHeader struct {
+	Version    *int           `json:"version"`
+	Directives jsonDirectives `json:"directives,omitempty"`
+}
+
+// jsonDirectives is a JSON object of directive values that keeps the order of
+// its keys. Values are written as strings, but numbers and booleans are read
+// too, as their literal text, so {"sample_rate": 48000} means the same as
+// {"sample_rate": "48000"}. Keys can't repeat, whatever their case.
+type jsonDirectives []Directive
+
+func (d jsonDirectives) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, directive := range d {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(directive.Key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(directive.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func (d *jsonDirectives) UnmarshalJSON(data []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return fmt.Errorf("\"directives\" must be an object")
+	}
+	*d = nil
+	seen := make(map[string]bool)
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			return fmt.Errorf("directive %q: %w", tok, err)
+		}
+		var value string
+		switch raw[0] {
+		case '"':
+			if err := json.Unmarshal(raw, &value); err != nil {
+				return fmt.Errorf("directive %q: %w", tok, err)
+			}
+		case '[', '{', 'n':
+			return fmt.Errorf("directive %q must be a string, number or boolean", tok)
+		default:
+			value = string(raw)
+		}
+		key := strings.ToLower(tok.(string))
+		if seen[key] {
+			return fmt.Errorf("duplicate directive %q", key)
+		}
+		seen[key] = true
+		*d = append(*d, Directive{Key: key, Value: value})
+	}
+	return nil
+}
+
+// parseJSON reads a JSON manifest document.
+func parseJSON(data []byte, opts Options) (*Manifest, error) {
+	var doc jsonDocument
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, jsonError(data, "invalid JSON", err)
+	}
+	if err := checkSchemaVersion(doc.Version); err != nil {
+		return nil, err
+	}
+	if doc.Entries == nil {
+		return nil, fmt.Errorf("missing \"entries\"")
+	}
+
+	m := &Manifest{Directives: doc.Directives, Entries: make([]ManifestEntry, 0, len(doc.Entries))}
+	if len(doc.Comments) > 0 {
+		m.Comments = doc.Comments
+	}
+	probs := newProblems(opts)
+	var offsets []int64
+	for i, je := range doc.Entries {
+		entry, err := je.toEntry()
+		if err != nil {
+			// Only a document with bad entries is read a second time, to
+			// find where they start.
+			if offsets == nil {
+				offsets = jsonEntryOffsets(data)
+			}
+			line, column := 0, 0
+			if i < len(offsets) {
+				line, column = offsetPosition(data, offsets[i])
+			}
+			if !probs.add(errorAt(line, column, "entry %d: %v", i+1, err)) {
+				break
+			}
+			continue
+		}
+		m.Entries = append(m.Entries, entry)
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// jsonEntryOffsets returns the byte offsets at which the entries of a JSON
+// manifest document start, or nil if data isn't such a document. Like
+// json.Unmarshal, it takes the last "entries" key, in any case.
+func jsonEntryOffsets(data []byte) []int64 {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('{') {
+		return nil
+	}
+	var offsets []int64
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil
+		}
+		var skip json.RawMessage
+		if name, _ := key.(string); !strings.EqualFold(name, "entries") {
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+			continue
+		}
+		if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+			return nil
+		}
+		offsets = offsets[:0]
+		for dec.More() {
+			// The decoder stops after the previous value, before any
+			// space and comma.
+			offset := dec.InputOffset()
+			for offset < int64(len(data)) && strings.IndexByte(" \t\r\n,", data[offset]) >= 0 {
+				offset++
+			}
+			offsets = append(offsets, offset)
+			if err := dec.Decode(&skip); err != nil {
+				return nil
+			}
+		}
+		if _, err := dec.Token(); err != nil {
+			return nil
+		}
+	}
+	return offsets
+}
+
+// parseJSONL reads a JSON Lines manifest.
+func parseJSONL(data []byte, opts Options) (*Manifest, error) {
+	m := &Manifest{}
+	probs := newProblems(opts)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(nil, 1<<20)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Bytes()
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+		if err := parseJSONLine(m, line); err != nil {
+			err.Line = lineNo
+			if !probs.add(err) {
+				break
+			}
+		}
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return m, nil
+}
+
+// parseJSONLine adds the header or entry on one line of a JSON Lines
+// manifest to m. The caller fills in the line of the error.
+func parseJSONLine(m *Manifest, line []byte) *ParseError {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(line, &fields); err != nil {
+		return jsonError(line, "invalid JSON", err)
+	}
+	if isJSONLHeader(fields) && len(m.Entries) == 0 {
+		var header jsonLinesHeader
+		if err := json.Unmarshal(line, &header); err != nil {
+			return jsonError(line, "invalid header", err)
+		}
+		if err := checkSchemaVersion(header.Version); err != nil {
+			return errorAt(0, 1, "%v", err)
+		}
+		m.Directives = header.Directives
+		return nil
+	}
+
+	var je jsonEntry
+	if err := json.Unmarshal(line, &je); err != nil {
+		return jsonError(line, "invalid entry", err)
+	}
+	entry, err := je.toEntry()
+	if err != nil {
+		return errorAt(0, 1, "%v", err)
+	}
+	m.Entries = append(m.Entries, entry)
+	return nil
+}
+
+// jsonError returns a ParseError for a failure to decode data, at the
+// position the decoder reports, if any.
+func jsonError(data []byte, what string, err error) *ParseError {
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		offset = typeErr.Offset
+	}
+	if offset < 0 {
+		return errorAt(0, 0, "%s: %v", what, err)
+	}
+	line, column := offsetPosition(data, offset)
+	return errorAt(line, column, "%s: %v", what, err)
+}
+
+// isJSONLHeader reports whether a JSON Lines object is the header line rather
+// than an entry.
+func isJSONLHeader(fields map[string]json.RawMessage) bool {
+	_, version := fields["version"]
+	_, directives := fields["directives"]
+	return version || directives
+}
+
+func checkSchemaVersion(version *int) error {
+	if version != nil && (*version < 1 || *version > SchemaVersion) {
+		return fmt.Errorf("unsupported schema version %d (this build supports up to %d)", *version, SchemaVersion)
+	}
+	return nil
+}
+
+func (je jsonEntry) toEntry() (ManifestEntry, error) {
+	switch {
+	case je.Start == nil:
+		return ManifestEntry{}, fmt.Errorf("missing \"start\"")
+	case je.End == nil:
+		return ManifestEntry{}, fmt.Errorf("missing \"end\"")
+	case je.Path == "":
+		return ManifestEntry{}, fmt.Errorf("missing \"path\"")
+	}
+	entry := ManifestEntry{
+		ID:        je.ID,
+		StartTime: *je.Start,
+		EndTime:   *je.End,
+		Speaker:   je.Speaker,
+		FilePath:  je.Path,
+		Text:      je.Text,
+		Gain:      je.Gain,
+	}
+	// Empty lists and objects are left out when writing, so they read as nil.
+	if len(je.Extra) > 0 {
+		entry.Extra = je.Extra
+	}
+	if len(je.Comments) > 0 {
+		entry.Comments = je.Comments
+	}
+	for _, w := range je.Words {
+		entry.Words = append(entry.Words, Word{Text: w.Word, Start: w.Start, End: w.End, Score: w.Score})
+	}
+	return entry, nil
+}
+
+func newJSONEntry(entry ManifestEntry) jsonEntry {
+	start, end := entry.StartTime, entry.EndTime
+	je := jsonEntry{
+		ID:       entry.ID,
+		Start:    &start,
+		End:      &end,
+		Speaker:  entry.Speaker,
+		Path:     entry.FilePath,
+		Text:     entry.Text,
+		Gain:     entry.Gain,
+		Extra:    entry.Extra,
+		Comments: entry.Comments,
+	}
+	for _, w := range entry.Words {
+		je.Words = append(je.Words, jsonWord{Word: w.Text, Start: w.Start, End: w.End, Score: w.Score})
+	}
+	return je
+}
+
+// writeJSON writes an indented JSON manifest document. Comments before
+// directives are not kept.
+func writeJSON(w io.Writer, m *Manifest, opts Options) error {
+	version := SchemaVersion
+	doc := jsonDocument{Version: &version, Directives: m.Directives, Entries: make([]jsonEntry, 0, len(m.Entries)), Comments: m.Comments}
+	for _, entry := range m.Entries {
+		doc.Entries = append(doc.Entries, newJSONEntry(entry))
+	}
+
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(doc); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	return nil
+}
+
+// writeJSONL writes a header line with the version and directives, followed
+// by one entry per line. Trailing comments are not kept.
+func writeJSONL(w io.Writer, m *Manifest, opts Options) error {
+	writer := bufio.NewWriter(w)
+	enc := json.NewEncoder(writer)
+	enc.SetEscapeHTML(false)
+
+	version := SchemaVersion
+	if err := enc.Encode(jsonLinesHeader{Version: &version, Directives: m.Directives}); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	for _, entry := range m.Entries {
+		if err := enc.Encode(newJSONEntry(entry)); err != nil {
+			return fmt.Errorf("failed to write to manifest: %w", err)
+		}
+	}
+	return writer.Flush()
+}
+
+// sniffJSON tells a JSON manifest document from JSON Lines: a first line
+// holding a complete object other than a document is taken as JSON Lines.
+// It returns false if data doesn't look like JSON at all.
+func sniffJSON(data []byte) (Format, bool) {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return "", false
+	}
+	first, rest, _ := bytes.Cut(trimmed, []byte("\n"))
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(first, &fields) != nil {
+		return FormatJSON, true
+	}
+	if _, ok := fields["entries"]; ok && len(bytes.TrimSpace(rest)) == 0 {
+		return FormatJSON, true
+	}
+	return FormatJSONL, true
+}
@@ -0,0 +1,188 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_JSON(t *testing.T) {
+	content := `{
+  "version": 1,
+  "entries": [
+    {"id": "intro", "start": 0, "end": 5.5, "speaker": "SPEAKER_00", "path": "/audio/000.wav", "text": "Hi.", "gain": -3},
+    {"start": 6.25, "end": 8, "path": "/audio/001.wav", "future_field": true}
+  ]
+}`
+	manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := Parse(manifestPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{ID: "intro", StartTime: 0, EndTime: 5.5, Speaker: "SPEAKER_00", FilePath: "/audio/000.wav", Text: "Hi.", Gain: -3},
+		{StartTime: 6.25, EndTime: 8, FilePath: "/audio/001.wav"},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestParse_JSON_Errors(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"newer version", `{"version": 2, "entries": []}`},
+		{"missing start", `{"version": 1, "entries": [{"end": 1, "path": "a.wav"}]}`},
+		{"missing path", `{"version": 1, "entries": [{"start": 0, "end": 1}]}`},
+		{"malformed", `{"version": 1, "entries": [`},
+		{"jsonl missing end", "{\"version\": 1}\n{\"start\": 0, \"path\": \"a.wav\"}\n"},
+		{"object directive", `{"version": 1, "directives": {"bed": {"path": "music.wav"}}, "entries": []}`},
+		{"null directive", `{"version": 1, "directives": {"bed": null}, "entries": []}`},
+		{"duplicate directive", `{"version": 1, "directives": {"bed": "a.wav", "Bed": "b.wav"}, "entries": []}`},
+		{"jsonl duplicate directive", "{\"version\": 1, \"directives\": {\"bed_gain\": -6, \"bed_gain\": -3}}\n"},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "manifest.json")
+			if err := os.WriteFile(manifestPath, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			if _, err := Parse(manifestPath); err == nil {
+				t.Error("expected an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestWrite_JSON_RoundTrip(t *testing.T) {
+	entries := []ManifestEntry{
+		{ID: "a", StartTime: 0.125, EndTime: 5, Speaker: "SPEAKER_00", FilePath: "/audio/<000>.wav", Text: "Line one.\nLine two.", Gain: 1.5, Extra: map[string]string{"take": "3"}},
+		{
+			StartTime: 3605.75, EndTime: 3608.4, Speaker: "SPEAKER_01", FilePath: "/audio/001.wav",
+			Words: []Word{{Text: "Bye", Start: 3605.75, End: 3606.0, Score: 0.9}, {Text: "now.", Start: 3606.1, End: 3606.5}},
+		},
+	}
+
+	for _, name := range []string{"manifest.json", "manifest.jsonl", "manifest.ndjson"} {
+		t.Run(name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), name)
+			if err := Write(manifestPath, entries); err != nil {
+				t.Fatalf("Write() error = %v", err)
+			}
+
+			data, err := os.ReadFile(manifestPath)
+			if err != nil {
+				t.Fatalf("failed to read manifest: %v", err)
+			}
+			if !strings.Contains(string(data), `"version"`) {
+				t.Errorf("expected a schema version in the output, got:\n%s", data)
+			}
+
+			parsed, err := Parse(manifestPath)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if len(parsed) != len(entries) {
+				t.Fatalf("expected %d entries, got %d", len(entries), len(parsed))
+			}
+			for i := range entries {
+				if !reflect.DeepEqual(parsed[i], entries[i]) {
+					t.Errorf("entry %d: expected %+v, got %+v", i, entries[i], parsed[i])
+				}
+			}
+		})
+	}
+}
+
+func TestDetectFormat_JSON(t *testing.T) {
+	testCases := []struct {
+		data     string
+		expected Format
+	}{
+		{`{"version": 1, "entries": []}`, FormatJSON},
+		{"{\n  \"version\": 1,\n  \"entries\": []\n}\n", FormatJSON},
+		{"{\"version\": 1}\n{\"start\": 0, \"end\": 1, \"path\": \"a.wav\"}\n", FormatJSONL},
+		{"{\"start\": 0, \"end\": 1, \"path\": \"a.wav\"}\n", FormatJSONL},
+	}
+
+	for _, tc := range testCases {
+		if got := DetectFormat("manifest", []byte(tc.data)); got != tc.expected {
+			t.Errorf("DetectFormat(%q) = %q, want %q", tc.data, got, tc.expected)
+		}
+	}
+}
+
+func TestWrite_JSON_Directives(t *testing.T) {
+	m := &Manifest{
+		Directives: []Directive{{Key: "sample_rate", Value: "48000"}, {Key: "bed", Value: "music.wav"}},
+		Entries:    []ManifestEntry{{StartTime: 0, EndTime: 1, FilePath: "/a.wav", Comments: []string{"# Act 1"}}},
+	}
+
+	for _, name := range []string{"manifest.json", "manifest.jsonl"} {
+		t.Run(name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), name)
+			if err := WriteManifest(manifestPath, m, Options{}); err != nil {
+				t.Fatalf("WriteManifest() error = %v", err)
+			}
+			parsed, err := Load(manifestPath, Options{})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(parsed.Directives, m.Directives) {
+				t.Errorf("expected directives %+v, got %+v", m.Directives, parsed.Directives)
+			}
+			if !reflect.DeepEqual(parsed.Entries, m.Entries) {
+				t.Errorf("expected entries %+v, got %+v", m.Entries, parsed.Entries)
+			}
+		})
+	}
+}
+
+func TestParse_JSON_DirectiveValues(t *testing.T) {
+	directives := `"directives": {"sample_rate": 48000, "max_speed": 1.30, "Bed_Gain": -6, "tempo": true, "bed": "music.wav"}`
+	expected := []Directive{
+		{Key: "sample_rate", Value: "48000"},
+		{Key: "max_speed", Value: "1.30"},
+		{Key: "bed_gain", Value: "-6"},
+		{Key: "tempo", Value: "true"},
+		{Key: "bed", Value: "music.wav"},
+	}
+
+	testCases := []struct {
+		name    string
+		content string
+	}{
+		{"manifest.json", `{"version": 1, ` + directives + `, "entries": []}`},
+		{"manifest.jsonl", `{"version": 1, ` + directives + "}\n"},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), tc.name)
+			if err := os.WriteFile(manifestPath, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			m, err := Load(manifestPath, Options{})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if !reflect.DeepEqual(m.Directives, expected) {
+				t.Errorf("expected directives %+v, got %+v", expected, m.Directives)
+			}
+		})
+	}
+}
@@ -1,11 +1,11 @@
 package manifest
 
 import (
-	"bufio"
+	"bytes"
 	"fmt"
+	"io"
 	"os"
-	"regexp"
-	"strconv"
+	"path/filepath"
 	"strings"
 )
 
@@ -15,75 +15,463 @@ type ManifestEntry struct {
 	EndTime   float64
 	Speaker   string
 	FilePath  string
+
+	// The fields below are optional and only kept by formats that support them.
+
+	// ID identifies the entry in upstream tools.
+	ID string
+	// Text is the transcript or subtitle text of the clip.
+	Text string
+	// Gain is applied to the clip when building, in decibels.
+	Gain float64
+	// Words holds word-level timings from the transcript, if known.
+	Words []Word
+	// Extra holds fields the manifest had beyond the ones above, such as
+	// unknown spreadsheet columns, keyed by their name in the source.
+	Extra map[string]string
+	// Comments holds the comment and blank lines just before the entry,
+	// verbatim.
+	Comments []string
+}
+
+// Word is a transcribed word with its position on the manifest timeline.
+type Word struct {
+	Text  string
+	Start float64
+	End   float64
+	// Score is the recognizer's confidence in the word, from 0 to 1.
+	Score float64
+}
+
+// Format identifies a manifest file format.
+type Format string
+
+const (
+	// FormatText is the native line format: [start–end] (speaker) path.
+	FormatText Format = "text"
+	// FormatSRT is SubRip subtitles, one entry per cue.
+	FormatSRT Format = "srt"
+	// FormatVTT is WebVTT subtitles, one entry per cue.
+	FormatVTT Format = "vtt"
+	// FormatJSON is a versioned JSON document; see SchemaVersion.
+	FormatJSON Format = "json"
+	// FormatJSONL is JSON Lines, one entry object per line.
+	FormatJSONL Format = "jsonl"
+	// FormatRTTM is diarization output, one entry per SPEAKER record.
+	FormatRTTM Format = "rttm"
+	// FormatWhisper is Whisper or WhisperX transcript JSON, one entry per segment.
+	FormatWhisper Format = "whisper"
+	// FormatAudacity is an Audacity label track, one entry per label.
+	FormatAudacity Format = "audacity"
+	// FormatCSV is a comma-separated table with a header row.
+	FormatCSV Format = "csv"
+	// FormatTSV is a tab-separated table with a header row.
+	FormatTSV Format = "tsv"
+	// FormatTextGrid is a Praat TextGrid, one entry per labelled interval.
+	FormatTextGrid Format = "textgrid"
+)
+
+// Writable reports whether entries can be written in the format.
+func (f Format) Writable() bool {
+	_, ok := writers[f]
+	return ok
+}
+
+// Options controls how entries are read from formats that don't carry every
+// field natively. The zero value uses the defaults documented on each field.
+type Options struct {
+	// Format forces the input format instead of detecting it.
+	Format Format
+	// SpeakerPattern is a regular expression matched against the start of cue
+	// text. Its first capture group is the speaker, and the whole match is
+	// removed from the text. Defaults to DefaultSpeakerPattern.
+	SpeakerPattern string
+	// PathTemplate builds clip paths for formats that don't name their clips,
+	// e.g. "clips/{index:03}.wav". See expandTemplate for the placeholders.
+	// Defaults to DefaultPathTemplate, except for TextGrids, where the
+	// interval label is the path.
+	PathTemplate string
+	// FrameRate is needed to read and write SMPTE timecode, and to snap to
+	// video frames.
+	FrameRate FrameRate
+	// SnapToFrames moves every start time to the nearest frame boundary.
+	SnapToFrames bool
+	// TimeFormat selects how the text format writes times. Defaults to
+	// TimeSeconds.
+	TimeFormat TimeFormat
+	// Columns maps entry fields (start, end, speaker, path, id, text and gain)
+	// to the header names of their columns in CSV and TSV manifests. Fields
+	// not listed use their own name as the header.
+	Columns map[string]string
+	// BaseDir is the directory that relative clip paths are resolved against
+	// when reading. Defaults to the directory of the manifest file.
+	BaseDir string
+	// CollectErrors makes line-based formats report every problem in the
+	// file as an ErrorList, instead of stopping at the first ParseError.
+	CollectErrors bool
+}
+
+// TimeFormat is a way of writing times in the text format.
+type TimeFormat string
+
+const (
+	// TimeSeconds writes times as seconds, e.g. "62.5s".
+	TimeSeconds TimeFormat = "seconds"
+	// TimeMilliseconds writes times as whole milliseconds, e.g. "62500ms".
+	TimeMilliseconds TimeFormat = "ms"
+	// TimeClock writes times as HH:MM:SS.mmm, e.g. "00:01:02.500".
+	TimeClock TimeFormat = "clock"
+	// TimeTimecode writes times as SMPTE timecode at Options.FrameRate, e.g.
+	// "00:01:02:12". Times between frames are rounded to the nearest frame.
+	TimeTimecode TimeFormat = "timecode"
+)
+
+const (
+	// DefaultSpeakerPattern matches upper-case labels such as "SPEAKER_01:".
+	DefaultSpeakerPattern = `^([A-Z][A-Z0-9_]*):\s*`
+	// DefaultPathTemplate names clips after their zero-based position.
+	DefaultPathTemplate = "{index:03}.wav"
+)
+
+// reader parses the contents of a manifest file.
+type reader func(data []byte, opts Options) (*Manifest, error)
+
+// writer serializes a manifest.
+type writer func(w io.Writer, m *Manifest, opts Options) error
+
+// entryReader and entryWriter handle formats that only hold entries, without
+// directives or trailing comments.
+type (
+	entryReader func(data []byte, opts Options) ([]ManifestEntry, error)
+	entryWriter func(w io.Writer, entries []ManifestEntry, opts Options) error
+)
+
+func readEntries(read entryReader) reader {
+	return func(data []byte, opts Options) (*Manifest, error) {
+		entries, err := read(data, opts)
+		if err != nil {
+			return nil, err
+		}
+		return &Manifest{Entries: entries}, nil
+	}
+}
+
+func writeEntries(write entryWriter) writer {
+	return func(w io.Writer, m *Manifest, opts Options) error {
+		return write(w, m.Entries, opts)
+	}
+}
+
+var readers = map[Format]reader{
+	FormatText:     parseText,
+	FormatSRT:      readEntries(parseSRT),
+	FormatVTT:      readEntries(parseVTT),
+	FormatJSON:     parseJSON,
+	FormatJSONL:    parseJSONL,
+	FormatRTTM:     readEntries(parseRTTM),
+	FormatWhisper:  readEntries(parseWhisper),
+	FormatAudacity: readEntries(parseAudacity),
+	FormatCSV:      parseCSV,
+	FormatTSV:      parseTSV,
+	FormatTextGrid: readEntries(parseTextGrid),
+}
+
+var writers = map[Format]writer{
+	FormatText:     writeText,
+	FormatVTT:      writeEntries(writeVTT),
+	FormatJSON:     writeJSON,
+	FormatJSONL:    writeJSONL,
+	FormatAudacity: writeEntries(writeAudacity),
+	FormatCSV:      writeCSV,
+	FormatTSV:      writeTSV,
+}
+
+// extensions maps lower-case file extensions to the format they usually hold.
+var extensions = map[string]Format{
+	".srt":      FormatSRT,
+	".vtt":      FormatVTT,
+	".json":     FormatJSON,
+	".jsonl":    FormatJSONL,
+	".ndjson":   FormatJSONL,
+	".rttm":     FormatRTTM,
+	".csv":      FormatCSV,
+	".tsv":      FormatTSV,
+	".textgrid": FormatTextGrid,
+}
+
+// FormatFromExtension returns the format conventionally stored under the
+// extension of path.
+func FormatFromExtension(path string) (Format, bool) {
+	format, ok := extensions[strings.ToLower(filepath.Ext(path))]
+	return format, ok
+}
+
+// ParseFormat returns the format named s, such as "srt" or "text". File
+// extensions are accepted too, e.g. "txt" or ".ndjson".
+func ParseFormat(s string) (Format, error) {
+	name := strings.ToLower(strings.TrimPrefix(strings.TrimSpace(s), "."))
+	if _, ok := readers[Format(name)]; ok {
+		return Format(name), nil
+	}
+	if name == "txt" {
+		return FormatText, nil
+	}
+	if format, ok := extensions["."+name]; ok {
+		return format, nil
+	}
+	return "", fmt.Errorf("unknown manifest format %q", s)
+}
+
+// DetectFormat guesses the format of a manifest from its file name, falling
+// back to sniffing its content. Unrecognized content is treated as text.
+func DetectFormat(path string, data []byte) Format {
+	if format, ok := FormatFromExtension(path); ok {
+		// Transcripts share the .json extension with JSON manifests.
+		if format == FormatJSON && looksLikeWhisper(data) {
+			return FormatWhisper
+		}
+		return format
+	}
+	if looksLikeWhisper(data) {
+		return FormatWhisper
+	}
+	if format, ok := sniffJSON(data); ok {
+		return format
+	}
+	if looksLikeVTT(data) {
+		return FormatVTT
+	}
+	if looksLikeTextGrid(data) {
+		return FormatTextGrid
+	}
+	if looksLikeSRT(data) {
+		return FormatSRT
+	}
+	if looksLikeRTTM(data) {
+		return FormatRTTM
+	}
+	if looksLikeAudacity(data) {
+		return FormatAudacity
+	}
+	return FormatText
+}
+
+// Manifest is a parsed manifest file.
+type Manifest struct {
+	// Format is the format the file was read in.
+	Format Format
+	// BaseDir is the directory relative paths in the file were resolved
+	// against.
+	BaseDir    string
+	Directives []Directive
+	Entries    []ManifestEntry
+	// Comments holds the comment and blank lines after the last entry,
+	// verbatim.
+	Comments []string
+	// Columns is the header row of a CSV or TSV manifest. Tables are written
+	// with their columns in this order.
+	Columns []string
+}
+
+// Directive is a "#! key=value" setting from a manifest header.
+type Directive struct {
+	// Key is lower case.
+	Key   string
+	Value string
+	// Comments holds the comment and blank lines just before the directive,
+	// verbatim.
+	Comments []string
+}
+
+// Directive returns the value of the directive named key.
+func (m *Manifest) Directive(key string) (string, bool) {
+	for _, d := range m.Directives {
+		if d.Key == key {
+			return d.Value, true
+		}
+	}
+	return "", false
+}
+
+// SetDirective sets the directive named key, adding it if it is missing.
+func (m *Manifest) SetDirective(key, value string) {
+	for i := range m.Directives {
+		if m.Directives[i].Key == key {
+			m.Directives[i].Value = value
+			return
+		}
+	}
+	m.Directives = append(m.Directives, Directive{Key: key, Value: value})
 }
 
 // Parse reads and parses the manifest file at the given path.
 func Parse(path string) ([]ManifestEntry, error) {
-	file, err := os.Open(path)
+	return ParseWithOptions(path, Options{})
+}
+
+// ParseWithOptions reads and parses the manifest file at the given path,
+// detecting its format unless opts.Format is set.
+func ParseWithOptions(path string, opts Options) ([]ManifestEntry, error) {
+	m, err := Load(path, opts)
+	if err != nil {
+		return nil, err
+	}
+	return m.Entries, nil
+}
+
+// Load is like ParseWithOptions but also reports the format of the file.
+// Problems with the content are reported as a *ParseError, or an ErrorList
+// with opts.CollectErrors.
+func Load(path string, opts Options) (*Manifest, error) {
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open manifest file: %w", err)
 	}
-	defer file.Close()
+	return decode(path, data, opts)
+}
 
-	// It captures start/end times (integer or float), speaker, and file path.
-	// It accepts both hyphen (-) and en dash (–) as separators.
-	re := regexp.MustCompile(`^\[(\d+(?:\.\d+)?)s[–-](\d+(?:\.\d+)?)s\]\s+\((.+)\)\s+(.+)$`)
+// Decode reads a manifest from r, such as standard input. name stands in for
+// the file name: it is used to detect the format unless opts.Format is set,
+// to locate errors, and, unless opts.BaseDir is set, its directory is the
+// one relative paths are resolved against.
+func Decode(r io.Reader, name string, opts Options) (*Manifest, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	return decode(name, data, opts)
+}
 
-	var entries []ManifestEntry
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := scanner.Text()
-		if strings.TrimSpace(line) == "" {
-			continue // Skip empty lines
-		}
+func decode(path string, data []byte, opts Options) (*Manifest, error) {
+	data = bytes.TrimPrefix(data, []byte("\ufeff"))
 
-		matches := re.FindStringSubmatch(line)
-		if len(matches) != 5 {
-			return nil, fmt.Errorf("failed to parse line: %q", line)
-		}
+	format := opts.Format
+	if format == "" {
+		format = DetectFormat(path, data)
+	}
+	read, ok := readers[format]
+	if !ok {
+		return nil, fmt.Errorf("unknown manifest format %q", format)
+	}
 
-		startTime, err := strconv.ParseFloat(matches[1], 64)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse start time: %w", err)
+	m, err := read(data, opts)
+	if err != nil {
+		return nil, withFile(path, err)
+	}
+	m.Format = format
+	m.BaseDir = baseDir(path, opts)
+	for i := range m.Entries {
+		m.Entries[i].FilePath = m.ResolvePath(m.Entries[i].FilePath)
+	}
+	if opts.SnapToFrames {
+		if opts.FrameRate.IsZero() {
+			return nil, fmt.Errorf("snapping to frames needs a frame rate")
 		}
-
-		endTime, err := strconv.ParseFloat(matches[2], 64)
-		if err != nil {
-			return nil, fmt.Errorf("failed to parse end time: %w", err)
+		for i := range m.Entries {
+			m.Entries[i].StartTime = opts.FrameRate.Snap(m.Entries[i].StartTime)
 		}
+	}
+	return m, nil
+}
 
-		entry := ManifestEntry{
-			StartTime: startTime,
-			EndTime:   endTime,
-			Speaker:   matches[3],
-			FilePath:  matches[4],
-		}
-		entries = append(entries, entry)
+// ResolvePath returns path relative to the manifest's BaseDir, unless it is
+// absolute.
+func (m *Manifest) ResolvePath(path string) string {
+	if path == "" || filepath.IsAbs(path) {
+		return path
 	}
+	return filepath.Join(m.BaseDir, path)
+}
 
-	if err := scanner.Err(); err != nil {
-		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+// baseDir returns the directory relative paths in the manifest at path are
+// resolved against.
+func baseDir(path string, opts Options) string {
+	if opts.BaseDir != "" {
+		return opts.BaseDir
 	}
+	return filepath.Dir(path)
+}
 
-	return entries, nil
+// RelativePath returns path relative to dir, climbing out of dir with ".."
+// if needed, so that it can be resolved against dir again. Paths that can't
+// be made relative, such as ones on another Windows volume, are returned as
+// absolute paths.
+func RelativePath(path, dir string) string {
+	if path == "" {
+		return path
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return path
+	}
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+	rel, err := filepath.Rel(absDir, absPath)
+	if err != nil {
+		return absPath
+	}
+	return filepath.ToSlash(rel)
 }
 
 // Write writes a slice of ManifestEntry structs to a file at the given path.
+// The format follows the file extension, defaulting to the text format.
 func Write(path string, entries []ManifestEntry) error {
+	return WriteWithOptions(path, entries, Options{})
+}
+
+// WriteWithOptions writes entries to a file at the given path in opts.Format,
+// or in the format implied by the file extension if that is not set.
+func WriteWithOptions(path string, entries []ManifestEntry, opts Options) error {
+	return WriteManifest(path, &Manifest{Entries: entries}, opts)
+}
+
+// WriteManifest is like WriteWithOptions but also writes the directives and
+// comments of m, in formats that can hold them. m.Format and m.BaseDir are
+// ignored. Clip paths are written relative to the directory of the new file.
+func WriteManifest(path string, m *Manifest, opts Options) error {
+	if opts.Format == "" {
+		opts.Format = FormatText
+		if f, ok := FormatFromExtension(path); ok && f.Writable() {
+			opts.Format = f
+		}
+	}
+	if !opts.Format.Writable() {
+		return fmt.Errorf("cannot write manifests in %s format", opts.Format)
+	}
+
 	file, err := os.Create(path)
 	if err != nil {
 		return fmt.Errorf("failed to create manifest file: %w", err)
 	}
 	defer file.Close()
 
-	writer := bufio.NewWriter(file)
-	for _, entry := range entries {
-		// Using ".1f" for consistency with the example format.
-		line := fmt.Sprintf("[%.1fs–%.1fs] (%s) %s\n", entry.StartTime, entry.EndTime, entry.Speaker, entry.FilePath)
-		if _, err := writer.WriteString(line); err != nil {
-			return fmt.Errorf("failed to write to manifest: %w", err)
-		}
+	relative := *m
+	relative.Entries = make([]ManifestEntry, len(m.Entries))
+	for i, entry := range m.Entries {
+		entry.FilePath = RelativePath(entry.FilePath, filepath.Dir(path))
+		relative.Entries[i] = entry
+	}
+	if err := Encode(file, &relative, opts); err != nil {
+		// Don't leave a manifest behind that reads back wrong.
+		file.Close()
+		os.Remove(path)
+		return err
 	}
+	return nil
+}
 
-	return writer.Flush()
+// Encode writes m to w, such as standard output, in opts.Format or else the
+// text format. Clip paths are written as they are.
+func Encode(w io.Writer, m *Manifest, opts Options) error {
+	format := opts.Format
+	if format == "" {
+		format = FormatText
+	}
+	write, ok := writers[format]
+	if !ok {
+		return fmt.Errorf("cannot write manifests in %s format", format)
+	}
+	return write(w, m, opts)
 }
@@ -1,8 +1,12 @@
 package manifest
 
 import (
+	"bytes"
+	"errors"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -77,3 +81,179 @@ invalid line
 		t.Error("expected an error for an invalid line, but got nil")
 	}
 }
+
+func TestLoad_RelativePaths(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "project", "manifest.txt")
+	if err := os.MkdirAll(filepath.Dir(manifestPath), 0755); err != nil {
+		t.Fatalf("failed to create project dir: %v", err)
+	}
+	content := "[0.0s–1.0s] (A) clips/000.wav\n[1.0s–2.0s] (A) ../shared/001.wav\n[2.0s–3.0s] (A) /abs/002.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	testCases := []struct {
+		name     string
+		baseDir  string
+		expected []string
+	}{
+		{"manifest dir", "", []string{
+			filepath.Join(tmpDir, "project", "clips", "000.wav"),
+			filepath.Join(tmpDir, "shared", "001.wav"),
+			"/abs/002.wav",
+		}},
+		{"base dir", "/media", []string{"/media/clips/000.wav", "/shared/001.wav", "/abs/002.wav"}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			entries, err := ParseWithOptions(manifestPath, Options{BaseDir: tc.baseDir})
+			if err != nil {
+				t.Fatalf("ParseWithOptions() error = %v", err)
+			}
+			for i, path := range tc.expected {
+				if entries[i].FilePath != path {
+					t.Errorf("entry %d: expected %s, got %s", i, path, entries[i].FilePath)
+				}
+			}
+		})
+	}
+}
+
+func TestWrite_RelativePaths(t *testing.T) {
+	// Manifests and clips in sibling directories of a project folder.
+	tmpDir := t.TempDir()
+	entries := []ManifestEntry{
+		{StartTime: 0, EndTime: 1, Speaker: "A", FilePath: filepath.Join(tmpDir, "manifests", "takes", "000.wav")},
+		{StartTime: 1, EndTime: 2, Speaker: "A", FilePath: filepath.Join(tmpDir, "clips", "001.wav")},
+	}
+
+	if err := os.Mkdir(filepath.Join(tmpDir, "manifests"), 0755); err != nil {
+		t.Fatalf("failed to create manifest dir: %v", err)
+	}
+	manifestPath := filepath.Join(tmpDir, "manifests", "manifest.txt")
+	if err := Write(manifestPath, entries); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	expected := "[0.0s–1.0s] (A) takes/000.wav\n[1.0s–2.0s] (A) ../clips/001.wav\n"
+	if string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+
+	parsed, err := Parse(manifestPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	for i := range entries {
+		if parsed[i].FilePath != entries[i].FilePath {
+			t.Errorf("entry %d: expected %s, got %s", i, entries[i].FilePath, parsed[i].FilePath)
+		}
+	}
+}
+
+func TestRoundTrip(t *testing.T) {
+	testCases := []struct {
+		name    string
+		content string
+		// exact is set if the written file should match the input byte for byte.
+		exact bool
+	}{
+		{"manifest.txt", "# Reel 1\n#! min_speed=0.85\n\n[5.75s–8.125s] (SPEAKER_00) clips/000.wav\n[00:00:09.001–10.0s] (SPEAKER_01) /abs/my (take 2).wav\n# end\n", false},
+		{"episode.vtt", "WEBVTT\n\nNOTE clip: clips/000.wav\n\nintro\n00:00:01.001 --> 00:00:04.250\n<v SPEAKER_01>Hello.\n", false},
+		{"manifest.json", `{"version": 1, "directives": {"bed": "music.wav"}, "entries": [{"start": 0.1, "end": 0.30000000000000004, "speaker": "", "path": "a.wav", "extra": {}, "comments": ["# x"]}], "comments": ["# end"]}`, false},
+		{"manifest.jsonl", "{\"version\": 1}\n{\"start\": 1e-7, \"end\": 2, \"path\": \"a.wav\", \"gain\": -3.5, \"words\": [{\"word\": \"hi\", \"start\": 1e-7, \"end\": 1}]}\n", false},
+		{"labels.txt", "0.500000\t2.750000\tSPEAKER_00|clips/000.wav\n3.000000\t4.123456789\t|odd|name.wav\n", true},
+		{"script.csv", "Scene,start,end,Notes,speaker,path\n1,0.5,2.75,\"Warm, slow\",ALICE,clips/000.wav\n2,3.001,4,,,clips/001.wav\n", true},
+		{"script.tsv", "start\tend\tpath\ttake\n0.333\t1\tclips/000.wav\t3\n", false},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), tc.name)
+			if err := os.WriteFile(manifestPath, []byte(tc.content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			first, err := Load(manifestPath, Options{})
+			if err != nil {
+				t.Fatalf("Load() error = %v", err)
+			}
+			if err := WriteManifest(manifestPath, first, Options{Format: first.Format}); err != nil {
+				t.Fatalf("WriteManifest() error = %v", err)
+			}
+			second, err := Load(manifestPath, Options{})
+			if err != nil {
+				t.Fatalf("Load() of the written manifest error = %v", err)
+			}
+			if !reflect.DeepEqual(first, second) {
+				t.Errorf("manifest changed in a round trip:\nbefore %+v\nafter  %+v", first, second)
+			}
+
+			if tc.exact {
+				data, err := os.ReadFile(manifestPath)
+				if err != nil {
+					t.Fatalf("failed to read manifest: %v", err)
+				}
+				if string(data) != tc.content {
+					t.Errorf("expected:\n%s\ngot:\n%s", tc.content, data)
+				}
+			}
+		})
+	}
+}
+
+func TestDecodeEncode(t *testing.T) {
+	in := strings.NewReader("1\n00:00:01,000 --> 00:00:02,500\nSPEAKER_01: Hello there.\n")
+	m, err := Decode(in, "<stdin>", Options{})
+	if err != nil {
+		t.Fatalf("Decode() error = %v", err)
+	}
+	if m.Format != FormatSRT {
+		t.Errorf("expected the format to be detected as srt, got %s", m.Format)
+	}
+
+	var out bytes.Buffer
+	if err := Encode(&out, m, Options{}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	expected := "[1.0s–2.5s] (SPEAKER_01) 000.wav\n"
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	if err := Encode(&out, m, Options{Format: FormatSRT}); err == nil {
+		t.Error("expected an error writing an unwritable format")
+	}
+}
+
+func TestDecode_ErrorName(t *testing.T) {
+	_, err := Decode(strings.NewReader("[1.0s–2.0s (A) a.wav\n"), "<stdin>", Options{})
+	var pe *ParseError
+	if !errors.As(err, &pe) || pe.File != "<stdin>" || pe.Line != 1 {
+		t.Errorf("expected a ParseError at <stdin>:1, got %v", err)
+	}
+}
+
+func TestParseFormat(t *testing.T) {
+	testCases := map[string]Format{
+		"text":     FormatText,
+		"txt":      FormatText,
+		"SRT":      FormatSRT,
+		".ndjson":  FormatJSONL,
+		"textgrid": FormatTextGrid,
+		"whisper":  FormatWhisper,
+	}
+	for name, expected := range testCases {
+		got, err := ParseFormat(name)
+		if err != nil || got != expected {
+			t.Errorf("ParseFormat(%q) = %q, %v; expected %q", name, got, err, expected)
+		}
+	}
+	if _, err := ParseFormat("docx"); err == nil {
+		t.Error("expected an error for an unknown format")
+	}
+}
@@ -0,0 +1,135 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// rttmSpeakerType is the RTTM record type for a speaker turn. Other record
+// types, such as SPKR-INFO or LEXEME, carry nothing a manifest needs.
+const rttmSpeakerType = "SPEAKER"
+
+// parseRTTM reads the SPEAKER records of a Rich Transcription Time Marked
+// file, as written by pyannote and other diarization tools:
+//
+//	SPEAKER <file> <channel> <onset> <duration> <NA> <NA> <speaker> <NA> <NA>
+//
+// Clip paths are built from opts.PathTemplate. Besides the common fields, the
+// template can use {file}, {channel}, {onset}, {onset_ms} and {duration}.
+func parseRTTM(data []byte, opts Options) ([]ManifestEntry, error) {
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = DefaultPathTemplate
+	}
+
+	var entries []ManifestEntry
+	probs := newProblems(opts)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := scanner.Text()
+		fields := strings.Fields(line)
+		if len(fields) == 0 || fields[0] != rttmSpeakerType {
+			continue
+		}
+		if len(fields) < 8 {
+			if !probs.add(errorAt(lineNo, 1, "expected at least 8 fields in SPEAKER record, got %d", len(fields))) {
+				break
+			}
+			continue
+		}
+
+		onset, err := strconv.ParseFloat(fields[3], 64)
+		if err != nil || onset < 0 {
+			if !probs.add(errorAt(lineNo, fieldColumn(line, 3), "invalid onset %q", fields[3])) {
+				break
+			}
+			continue
+		}
+		duration, err := strconv.ParseFloat(fields[4], 64)
+		if err != nil || duration < 0 {
+			if !probs.add(errorAt(lineNo, fieldColumn(line, 4), "invalid duration %q", fields[4])) {
+				break
+			}
+			continue
+		}
+		speaker := fields[7]
+		if speaker == "<NA>" {
+			speaker = ""
+		}
+
+		path, err := expandTemplate(tmpl, templateFields{
+			"index":    len(entries),
+			"number":   len(entries) + 1,
+			"speaker":  speaker,
+			"start":    onset,
+			"end":      onset + duration,
+			"file":     fields[1],
+			"channel":  fields[2],
+			"onset":    onset,
+			"onset_ms": int64(math.Round(onset * 1000)),
+			"duration": duration,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, ManifestEntry{
+			StartTime: onset,
+			EndTime:   onset + duration,
+			Speaker:   speaker,
+			FilePath:  path,
+		})
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return entries, nil
+}
+
+// fieldColumn returns the one-based column of the nth whitespace-separated
+// field of line.
+func fieldColumn(line string, n int) int {
+	inField := false
+	for i, r := range line {
+		if unicode.IsSpace(r) {
+			inField = false
+			continue
+		}
+		if !inField {
+			if n == 0 {
+				return utf8.RuneCountInString(line[:i]) + 1
+			}
+			n--
+			inField = true
+		}
+	}
+	return 0
+}
+
+// looksLikeRTTM reports whether the first record in data is an RTTM
+// SPEAKER record with a numeric onset and duration.
+func looksLikeRTTM(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 || strings.HasPrefix(fields[0], ";;") {
+			continue
+		}
+		if fields[0] != rttmSpeakerType || len(fields) < 8 {
+			return false
+		}
+		_, onsetErr := strconv.ParseFloat(fields[3], 64)
+		_, durationErr := strconv.ParseFloat(fields[4], 64)
+		return onsetErr == nil && durationErr == nil
+	}
+	return false
+}
@@ -0,0 +1,78 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_RTTM(t *testing.T) {
+	content := `;; produced by pyannote
+SPKR-INFO episode 1 <NA> <NA> <NA> unknown SPEAKER_00 <NA> <NA>
+SPEAKER episode 1 0.500 2.250 <NA> <NA> SPEAKER_00 <NA> <NA>
+SPEAKER episode 1 3.031 1.969 <NA> <NA> SPEAKER_01 <NA> <NA>
+
+SPEAKER episode 1 5.000 0.500 <NA> <NA> <NA> 0.87 <NA>
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "episode.rttm")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "{file}/{index:03}_{onset_ms}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{StartTime: 0.5, EndTime: 2.75, Speaker: "SPEAKER_00", FilePath: filepath.Join(tmpDir, "episode/000_500.wav")},
+		{StartTime: 3.031, EndTime: 5.0, Speaker: "SPEAKER_01", FilePath: filepath.Join(tmpDir, "episode/001_3031.wav")},
+		{StartTime: 5.0, EndTime: 5.5, Speaker: "", FilePath: filepath.Join(tmpDir, "episode/002_5000.wav")},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestParse_RTTM_Errors(t *testing.T) {
+	testCases := map[string]string{
+		"short record":   "SPEAKER episode 1 0.5 2.0 <NA> <NA>\n",
+		"invalid onset":  "SPEAKER episode 1 abc 2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\n",
+		"negative dur":   "SPEAKER episode 1 0.5 -2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\n",
+		"bad template":   "SPEAKER episode 1 0.5 2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\n",
+		"invalid second": "SPEAKER episode 1 0.5 2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\nSPEAKER episode 1 x 2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\n",
+	}
+
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "episode.rttm")
+			if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			opts := Options{}
+			if name == "bad template" {
+				opts.PathTemplate = "{text}.wav"
+			}
+			if _, err := ParseWithOptions(manifestPath, opts); err == nil {
+				t.Error("expected an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestDetectFormat_RTTM(t *testing.T) {
+	data := []byte(";; header\nSPEAKER ep 1 0.5 2.0 <NA> <NA> SPEAKER_00 <NA> <NA>\n")
+	if got := DetectFormat("diarization.out", data); got != FormatRTTM {
+		t.Errorf("DetectFormat() = %q, want %q", got, FormatRTTM)
+	}
+	if got := DetectFormat("manifest.txt", []byte("[0.0s–1.0s] (SPEAKER_00) /a.wav\n")); got != FormatText {
+		t.Errorf("DetectFormat() = %q, want %q", got, FormatText)
+	}
+}
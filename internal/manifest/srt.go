@@ -0,0 +1,194 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// srtTimingRe matches a SubRip timing line. Hours are optional and a dot is
+// accepted in place of the comma, as many tools write them that way.
+var srtTimingRe = regexp.MustCompile(`^\s*((?:\d+:)?\d{1,2}:\d{1,2}[,.]\d{1,3})\s*-->\s*((?:\d+:)?\d{1,2}:\d{1,2}[,.]\d{1,3})`)
+
+// srtCue is a single subtitle block.
+type srtCue struct {
+	number int
+	start  float64
+	end    float64
+	text   []string
+}
+
+// parseSRT reads SubRip cues. The speaker is taken from the start of the cue
+// text using opts.SpeakerPattern, and the clip path is built from
+// opts.PathTemplate. Besides the common fields, the template can use {text}.
+func parseSRT(data []byte, opts Options) ([]ManifestEntry, error) {
+	cues, err := readSRTCues(data, newProblems(opts))
+	if err != nil {
+		return nil, err
+	}
+
+	speakerRe, err := compileSpeakerPattern(opts.SpeakerPattern)
+	if err != nil {
+		return nil, err
+	}
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = DefaultPathTemplate
+	}
+
+	entries := make([]ManifestEntry, 0, len(cues))
+	for i, cue := range cues {
+		speaker, text := splitSpeaker(speakerRe, strings.Join(cue.text, "\n"))
+
+		path, err := expandTemplate(tmpl, templateFields{
+			"index":   i,
+			"number":  cue.number,
+			"speaker": speaker,
+			"start":   cue.start,
+			"end":     cue.end,
+			"text":    text,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, ManifestEntry{
+			StartTime: cue.start,
+			EndTime:   cue.end,
+			Speaker:   speaker,
+			FilePath:  path,
+			Text:      text,
+		})
+	}
+	return entries, nil
+}
+
+// readSRTCues splits SubRip data into cues. Blocks are separated by blank
+// lines; the numeric counter before the timing line is optional. A block with
+// a problem is skipped when collecting problems.
+func readSRTCues(data []byte, probs *problems) ([]srtCue, error) {
+	var cues []srtCue
+	var cue *srtCue
+	pendingNumber := 0
+	skipping := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+
+		if strings.TrimSpace(line) == "" {
+			cue = nil
+			skipping = false
+			continue
+		}
+		if skipping {
+			continue
+		}
+		if cue != nil {
+			cue.text = append(cue.text, line)
+			continue
+		}
+
+		if m := srtTimingRe.FindStringSubmatch(line); m != nil {
+			start, err := parseSRTTimestamp(m[1])
+			var end float64
+			if err == nil {
+				end, err = parseSRTTimestamp(m[2])
+			}
+			if err != nil {
+				if !probs.add(errorAt(lineNo, 1, "%v", err)) {
+					break
+				}
+				skipping, pendingNumber = true, 0
+				continue
+			}
+			number := pendingNumber
+			if number == 0 {
+				number = len(cues) + 1
+			}
+			cues = append(cues, srtCue{number: number, start: start, end: end})
+			cue = &cues[len(cues)-1]
+			pendingNumber = 0
+			continue
+		}
+
+		n, err := strconv.Atoi(strings.TrimSpace(line))
+		if err != nil || pendingNumber != 0 {
+			if !probs.add(errorAt(lineNo, 1, "expected a cue number or timing line, got %q", line)) {
+				break
+			}
+			skipping, pendingNumber = true, 0
+			continue
+		}
+		pendingNumber = n
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return cues, nil
+}
+
+// parseSRTTimestamp converts "hh:mm:ss,mmm" (hours optional) to seconds.
+func parseSRTTimestamp(s string) (float64, error) {
+	s = strings.Replace(s, ",", ".", 1)
+	parts := strings.Split(s, ":")
+
+	var seconds float64
+	for _, part := range parts {
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid timestamp %q", s)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+// looksLikeSRT reports whether data starts like a SubRip file.
+func looksLikeSRT(data []byte) bool {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		if srtTimingRe.MatchString(line) {
+			return true
+		}
+		if _, err := strconv.Atoi(line); err != nil {
+			return false
+		}
+		return scanner.Scan() && srtTimingRe.MatchString(scanner.Text())
+	}
+	return false
+}
+
+func compileSpeakerPattern(pattern string) (*regexp.Regexp, error) {
+	if pattern == "" {
+		pattern = DefaultSpeakerPattern
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid speaker pattern: %w", err)
+	}
+	if re.NumSubexp() < 1 {
+		return nil, fmt.Errorf("speaker pattern %q has no capture group", pattern)
+	}
+	return re, nil
+}
+
+// splitSpeaker separates a speaker prefix from cue text. Text without a
+// matching prefix has no speaker.
+func splitSpeaker(re *regexp.Regexp, text string) (speaker, rest string) {
+	loc := re.FindStringSubmatchIndex(text)
+	if loc == nil || loc[0] != 0 || loc[2] < 0 {
+		return "", text
+	}
+	return text[loc[2]:loc[3]], text[loc[1]:]
+}
@@ -0,0 +1,72 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_SRT(t *testing.T) {
+	content := "\ufeff1\r\n00:00:01,000 --> 00:00:04,250\r\nSPEAKER_01: Hello there.\r\nHow are you?\r\n\r\n" +
+		"2\n00:01:02.500 --> 00:01:05,000\nNo speaker on this one.\n\n" +
+		"7\n01:00:00,000 --> 01:00:01,000 X1:40 X2:600\nSPEAKER_00: Bye.\n"
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "episode.srt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "clips/{index:03}_{number}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{StartTime: 1.0, EndTime: 4.25, Speaker: "SPEAKER_01", FilePath: filepath.Join(tmpDir, "clips/000_1.wav"), Text: "Hello there.\nHow are you?"},
+		{StartTime: 62.5, EndTime: 65.0, Speaker: "", FilePath: filepath.Join(tmpDir, "clips/001_2.wav"), Text: "No speaker on this one."},
+		{StartTime: 3600.0, EndTime: 3601.0, Speaker: "SPEAKER_00", FilePath: filepath.Join(tmpDir, "clips/002_7.wav"), Text: "Bye."},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestParse_SRT_SpeakerPattern(t *testing.T) {
+	content := "1\n00:00:00,000 --> 00:00:02,000\n[Alice] Hi!\n"
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "subs.txt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	// Detected by content, since the extension says nothing.
+	entries, err := ParseWithOptions(manifestPath, Options{SpeakerPattern: `^\[(\w+)\]\s*`})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if len(entries) != 1 || entries[0].Speaker != "Alice" || entries[0].FilePath != filepath.Join(tmpDir, "000.wav") {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestParse_SRT_InvalidTiming(t *testing.T) {
+	content := "1\n00:00:00,000 --> 00:00:02,000\nfine\n\n2\nnot a timing line\n"
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "subs.srt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for a malformed cue, but got nil")
+	}
+}
@@ -0,0 +1,55 @@
+package manifest
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"unicode"
+)
+
+// placeholderRe matches "{name}" and "{name:spec}" in a path template.
+var placeholderRe = regexp.MustCompile(`\{(\w+)(?::([^}]*))?\}`)
+
+// templateFields are the values a path template can refer to.
+type templateFields map[string]any
+
+// expandTemplate replaces every placeholder in tmpl with the matching field.
+// The optional spec is a printf width/precision applied to the value, so
+// "{index:03}" renders index 7 as "007" and "{start:.2}" renders 1.5 as "1.50".
+// Which fields exist depends on the source format; every format provides
+// index (zero-based position), number (one-based), speaker, start and end.
+func expandTemplate(tmpl string, fields templateFields) (string, error) {
+	var expandErr error
+	result := placeholderRe.ReplaceAllStringFunc(tmpl, func(placeholder string) string {
+		m := placeholderRe.FindStringSubmatch(placeholder)
+		name, spec := m[1], m[2]
+
+		value, ok := fields[name]
+		if !ok {
+			if expandErr == nil {
+				expandErr = fmt.Errorf("unknown placeholder {%s} in path template %q", name, tmpl)
+			}
+			return placeholder
+		}
+		return formatField(value, spec)
+	})
+	return result, expandErr
+}
+
+func formatField(value any, spec string) string {
+	// A spec ending in a verb, such as "x" or "s", is used as-is.
+	if spec != "" && unicode.IsLetter(rune(spec[len(spec)-1])) {
+		return fmt.Sprintf("%"+spec, value)
+	}
+	switch v := value.(type) {
+	case int:
+		return fmt.Sprintf("%"+spec+"d", v)
+	case float64:
+		if spec == "" {
+			return strconv.FormatFloat(v, 'f', -1, 64)
+		}
+		return fmt.Sprintf("%"+spec+"f", v)
+	default:
+		return fmt.Sprintf("%"+spec+"v", v)
+	}
+}
@@ -0,0 +1,30 @@
+package manifest
+
+import "testing"
+
+func TestExpandTemplate(t *testing.T) {
+	fields := templateFields{"index": 7, "speaker": "SPEAKER_01", "start": 1.5}
+
+	testCases := []struct {
+		template string
+		expected string
+	}{
+		{"clips/{index:03}.wav", "clips/007.wav"},
+		{"{speaker}/{index}.wav", "SPEAKER_01/7.wav"},
+		{"{start}.wav", "1.5.wav"},
+		{"{start:.2}.wav", "1.50.wav"},
+		{"{index:x}.wav", "7.wav"},
+	}
+	for _, tc := range testCases {
+		got, err := expandTemplate(tc.template, fields)
+		if err != nil {
+			t.Errorf("expandTemplate(%q) error = %v", tc.template, err)
+		} else if got != tc.expected {
+			t.Errorf("expandTemplate(%q): expected %q, got %q", tc.template, tc.expected, got)
+		}
+	}
+
+	if _, err := expandTemplate("{missing}.wav", fields); err == nil {
+		t.Error("expected an error for an unknown placeholder, but got nil")
+	}
+}
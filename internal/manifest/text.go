@@ -0,0 +1,375 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// textSeparators are the accepted separators between start and end time,
+// longest first so "-->" isn't read as "-".
+var textSeparators = []string{"-->", "–", "—", "-"}
+
+// parseText parses the native line format:
+//
+//	[<start><sep><end>] (<speaker>) <path>
+//
+// See parseTextTime for the accepted times; <sep> is one of textSeparators,
+// optionally surrounded by spaces. The speaker may be empty, for entries
+// imported from formats that don't always name one. It ends at the first ")"
+// followed by a space, and the rest of the line is the path, taken as-is.
+//
+// Lines starting with "#" are comments. Header lines of the form
+// "#! key=value" are directives, and must come before the first entry.
+// Comments and blank lines are kept with the entry or directive that follows
+// them.
+func parseText(data []byte, opts Options) (*Manifest, error) {
+	m := &Manifest{}
+	probs := newProblems(opts)
+	var comments []string
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		trimmed := strings.TrimSpace(line)
+		column := utf8.RuneCountInString(line[:strings.Index(line, trimmed)]) + 1
+		var lineErr *ParseError
+		switch {
+		case trimmed == "":
+			comments = append(comments, "")
+		case strings.HasPrefix(trimmed, "#!"):
+			d, ok := parseDirective(trimmed[len("#!"):])
+			switch {
+			case len(m.Entries) > 0:
+				lineErr = errorAt(lineNo, column, "directives must come before the first entry")
+			case !ok:
+				lineErr = errorAt(lineNo, column, "invalid directive %q (expected \"#! key=value\")", trimmed)
+			default:
+				if _, dup := m.Directive(d.Key); dup {
+					lineErr = errorAt(lineNo, column, "duplicate directive %q", d.Key)
+					break
+				}
+				d.Comments = comments
+				comments = nil
+				m.Directives = append(m.Directives, d)
+			}
+		case strings.HasPrefix(trimmed, "#"):
+			comments = append(comments, line)
+		default:
+			entry, err := parseTextLine(line, opts.FrameRate)
+			if err != nil {
+				err.Line = lineNo
+				lineErr = err
+				break
+			}
+			entry.Comments = comments
+			comments = nil
+			m.Entries = append(m.Entries, entry)
+		}
+		if lineErr != nil && !probs.add(lineErr) {
+			break
+		}
+	}
+
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	m.Comments = comments
+
+	return m, nil
+}
+
+// parseDirective parses the "key=value" part of a directive line.
+func parseDirective(s string) (Directive, bool) {
+	key, value, ok := strings.Cut(s, "=")
+	key = strings.ToLower(strings.TrimSpace(key))
+	if !ok || key == "" || strings.ContainsAny(key, " \t") {
+		return Directive{}, false
+	}
+	return Directive{Key: key, Value: strings.TrimSpace(value)}, true
+}
+
+// textLine is a cursor over a single manifest line.
+type textLine struct {
+	s   string
+	pos int
+}
+
+// errorf reports a problem at the current (one-based) column. The caller
+// fills in the line.
+func (l *textLine) errorf(format string, args ...any) *ParseError {
+	return errorAt(0, utf8.RuneCountInString(l.s[:l.pos])+1, format, args...)
+}
+
+func (l *textLine) skipSpace() {
+	for l.pos < len(l.s) && (l.s[l.pos] == ' ' || l.s[l.pos] == '\t') {
+		l.pos++
+	}
+}
+
+// accept consumes lit if the line continues with it.
+func (l *textLine) accept(lit string) bool {
+	if strings.HasPrefix(l.s[l.pos:], lit) {
+		l.pos += len(lit)
+		return true
+	}
+	return false
+}
+
+// time reads a time token: digits, ".", ",", ":", ";" and a unit suffix.
+func (l *textLine) time(rate FrameRate) (float64, *ParseError) {
+	start := l.pos
+	for l.pos < len(l.s) && strings.IndexByte("0123456789.,:;ms", l.s[l.pos]) >= 0 {
+		l.pos++
+	}
+	token := l.s[start:l.pos]
+	// Quote all of what was written in errors, e.g. "1e3s" rather than the
+	// "1" that was read of it.
+	written := token
+	if end := strings.IndexAny(l.s[l.pos:], " \t]–—-"); end != 0 {
+		if end < 0 {
+			end = len(l.s) - l.pos
+		}
+		written = l.s[start : l.pos+end]
+	}
+	if written == "" {
+		return 0, l.errorf("expected a time")
+	}
+	if written != token {
+		l.pos = start
+		return 0, l.errorf("invalid time %q (%s)", written, textTimeForms)
+	}
+	seconds, err := parseTextTime(token, rate)
+	if err != nil {
+		l.pos = start
+		return 0, l.errorf("%v", err)
+	}
+	return seconds, nil
+}
+
+func parseTextLine(s string, rate FrameRate) (ManifestEntry, *ParseError) {
+	l := &textLine{s: s}
+	var entry ManifestEntry
+	var err *ParseError
+
+	l.skipSpace()
+	if !l.accept("[") {
+		return ManifestEntry{}, l.errorf("expected \"[\"")
+	}
+	l.skipSpace()
+	if entry.StartTime, err = l.time(rate); err != nil {
+		return ManifestEntry{}, err
+	}
+	l.skipSpace()
+	separated := false
+	for _, sep := range textSeparators {
+		if l.accept(sep) {
+			separated = true
+			break
+		}
+	}
+	if !separated {
+		return ManifestEntry{}, l.errorf("expected \"–\", \"-\" or \"-->\" between start and end time")
+	}
+	l.skipSpace()
+	if entry.EndTime, err = l.time(rate); err != nil {
+		return ManifestEntry{}, err
+	}
+	l.skipSpace()
+	if !l.accept("]") {
+		return ManifestEntry{}, l.errorf("expected \"]\"")
+	}
+
+	l.skipSpace()
+	if !l.accept("(") {
+		return ManifestEntry{}, l.errorf("expected \"(\" before the speaker")
+	}
+	end := -1
+	for i := l.pos; i < len(s); i++ {
+		if s[i] == ')' && i+1 < len(s) && unicode.IsSpace(rune(s[i+1])) {
+			end = i
+			break
+		}
+	}
+	if end < 0 {
+		return ManifestEntry{}, l.errorf("expected \")\" and a file path after the speaker")
+	}
+	entry.Speaker = s[l.pos:end]
+	l.pos = end + 1
+
+	l.skipSpace()
+	if l.pos == len(s) {
+		return ManifestEntry{}, l.errorf("expected a file path")
+	}
+	entry.FilePath = s[l.pos:]
+	return entry, nil
+}
+
+// textTimeForms lists examples of the accepted times, for errors.
+const textTimeForms = "expected e.g. 12.5s, 12500ms or 00:00:12.500"
+
+// parseTextTime converts a time to seconds. It accepts seconds ("12.5s"),
+// milliseconds ("12500ms"), clock time ("HH:MM:SS.mmm" or "MM:SS.mmm", with
+// "." or "," before the fraction) and SMPTE timecode ("HH:MM:SS:FF", which
+// needs a frame rate).
+func parseTextTime(s string, rate FrameRate) (float64, error) {
+	if ms, ok := strings.CutSuffix(s, "ms"); ok {
+		v, err := strconv.ParseFloat(ms, 64)
+		if err != nil || !isDecimal(ms) {
+			return 0, fmt.Errorf("invalid time %q (%s)", s, textTimeForms)
+		}
+		return v / 1000, nil
+	}
+	if seconds, ok := strings.CutSuffix(s, "s"); ok {
+		v, err := strconv.ParseFloat(seconds, 64)
+		if err != nil || !isDecimal(seconds) {
+			return 0, fmt.Errorf("invalid time %q (%s)", s, textTimeForms)
+		}
+		return v, nil
+	}
+
+	parts := strings.Split(s, ":")
+	if len(parts) == 4 || strings.Contains(s, ";") {
+		frame, err := rate.ParseTimecode(s)
+		if err != nil {
+			return 0, err
+		}
+		return rate.FrameSeconds(frame), nil
+	}
+	if len(parts) < 2 || len(parts) > 3 {
+		return 0, fmt.Errorf("invalid time %q (%s)", s, textTimeForms)
+	}
+
+	var seconds float64
+	for i, part := range parts {
+		last := i == len(parts)-1
+		if last {
+			part = strings.Replace(part, ",", ".", 1)
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil || !isDecimal(part) || (!last && strings.Contains(part, ".")) || (i > 0 && v >= 60) {
+			return 0, fmt.Errorf("invalid time %q (%s)", s, textTimeForms)
+		}
+		seconds = seconds*60 + v
+	}
+	return seconds, nil
+}
+
+// isDecimal reports whether s is digits with an optional fraction, which
+// rules out forms ParseFloat also accepts, such as "1e3" or "Inf".
+func isDecimal(s string) bool {
+	whole, frac, _ := strings.Cut(s, ".")
+	return whole != "" && strings.Trim(whole, "0123456789") == "" && strings.Trim(frac, "0123456789") == ""
+}
+
+// writeText writes a manifest in the native line format, with its directives
+// and comments.
+func writeText(w io.Writer, m *Manifest, opts Options) error {
+	var formatTime func(seconds float64) string
+	switch opts.TimeFormat {
+	case "", TimeSeconds:
+		formatTime = func(seconds float64) string {
+			return formatDecimal(seconds, 1) + "s"
+		}
+	case TimeMilliseconds:
+		formatTime = func(seconds float64) string {
+			return fmt.Sprintf("%dms", int64(math.Round(seconds*1000)))
+		}
+	case TimeClock:
+		formatTime = formatVTTTimestamp
+	case TimeTimecode:
+		if opts.FrameRate.IsZero() {
+			return fmt.Errorf("writing timecode needs a frame rate")
+		}
+		formatTime = func(seconds float64) string {
+			return opts.FrameRate.FormatTimecode(opts.FrameRate.NearestFrame(seconds))
+		}
+	default:
+		return fmt.Errorf("unknown time format %q", opts.TimeFormat)
+	}
+
+	// Check every entry first, so nothing is written for a manifest that
+	// would read back differently.
+	for i, entry := range m.Entries {
+		if !textSpeakerWritable(entry.Speaker) {
+			return fmt.Errorf("entry %d: speaker %q can't be written in the text format", i+1, entry.Speaker)
+		}
+		if !textPathWritable(entry.FilePath) {
+			return fmt.Errorf("entry %d: path %q can't be written in the text format", i+1, entry.FilePath)
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	for _, d := range m.Directives {
+		writeComments(writer, d.Comments)
+		fmt.Fprintf(writer, "#! %s=%s\n", d.Key, d.Value)
+	}
+	for _, entry := range m.Entries {
+		writeComments(writer, entry.Comments)
+		fmt.Fprintf(writer, "[%s–%s] (%s) %s\n", formatTime(entry.StartTime), formatTime(entry.EndTime), entry.Speaker, entry.FilePath)
+	}
+	writeComments(writer, m.Comments)
+
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	return nil
+}
+
+// textSpeakerWritable reports whether speaker reads back unchanged from a
+// text manifest line: it can't hold a line break, nor a ")" followed by a
+// space, which parseTextLine takes as its end.
+func textSpeakerWritable(speaker string) bool {
+	if strings.ContainsAny(speaker, "\r\n") {
+		return false
+	}
+	for i := 0; i < len(speaker)-1; i++ {
+		if speaker[i] == ')' && unicode.IsSpace(rune(speaker[i+1])) {
+			return false
+		}
+	}
+	return true
+}
+
+// textPathWritable reports whether path reads back unchanged from a text
+// manifest line: it can't be empty, start with a space or hold a line break.
+func textPathWritable(path string) bool {
+	return path != "" && path[0] != ' ' && path[0] != '\t' && !strings.ContainsAny(path, "\r\n")
+}
+
+// formatDecimal formats v with as many digits as it takes to read it back
+// exactly, but at least minFrac digits after the decimal point.
+func formatDecimal(v float64, minFrac int) string {
+	s := strconv.FormatFloat(v, 'f', -1, 64)
+	_, frac, hasPoint := strings.Cut(s, ".")
+	if len(frac) >= minFrac {
+		return s
+	}
+	if !hasPoint {
+		s += "."
+	}
+	return s + strings.Repeat("0", minFrac-len(frac))
+}
+
+// writeComments writes comment lines verbatim, adding the "#" marker to those
+// that lack it.
+func writeComments(w *bufio.Writer, comments []string) {
+	for _, c := range comments {
+		if strings.TrimSpace(c) == "" {
+			w.WriteString("\n")
+			continue
+		}
+		if !strings.HasPrefix(strings.TrimSpace(c), "#") {
+			c = "# " + c
+		}
+		w.WriteString(c + "\n")
+	}
+}
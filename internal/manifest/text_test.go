@@ -0,0 +1,220 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseTextLine(t *testing.T) {
+	testCases := []struct {
+		line     string
+		expected ManifestEntry
+	}{
+		{"[0.0s–5.0s] (SPEAKER_00) /audio/000.wav", ManifestEntry{StartTime: 0, EndTime: 5, Speaker: "SPEAKER_00", FilePath: "/audio/000.wav"}},
+		{"[5s-8.4s] () clips/001.wav", ManifestEntry{StartTime: 5, EndTime: 8.4, FilePath: "clips/001.wav"}},
+		{"[00:01:02.500 --> 00:01:05,250] (A) a.wav", ManifestEntry{StartTime: 62.5, EndTime: 65.25, Speaker: "A", FilePath: "a.wav"}},
+		{"[01:02.5 --> 1:05] (A) a.wav", ManifestEntry{StartTime: 62.5, EndTime: 65, Speaker: "A", FilePath: "a.wav"}},
+		{"[1234ms–2000ms](A)\tb.wav", ManifestEntry{StartTime: 1.234, EndTime: 2, Speaker: "A", FilePath: "b.wav"}},
+		{"[1s–2s] (John (narrator)) /my clips/take (2) final.wav", ManifestEntry{StartTime: 1, EndTime: 2, Speaker: "John (narrator)", FilePath: "/my clips/take (2) final.wav"}},
+		{"[1s–2s] (A) /odd/path) [x] –.wav", ManifestEntry{StartTime: 1, EndTime: 2, Speaker: "A", FilePath: "/odd/path) [x] –.wav"}},
+	}
+
+	for _, tc := range testCases {
+		entry, err := parseTextLine(tc.line, FrameRate{})
+		if err != nil {
+			t.Errorf("parseTextLine(%q) error = %v", tc.line, err)
+			continue
+		}
+		if !reflect.DeepEqual(entry, tc.expected) {
+			t.Errorf("parseTextLine(%q) = %+v, want %+v", tc.line, entry, tc.expected)
+		}
+	}
+}
+
+func TestParseTextLine_Errors(t *testing.T) {
+	testCases := map[string]int{
+		"0.0s–5.0s (A) a.wav":         1,
+		"[0.0–5.0s] (A) a.wav":        2,
+		"[0.0s 5.0s] (A) a.wav":       7,
+		"[0.0s–5.0s (A) a.wav":        12,
+		"[0.0s–5.0s] A a.wav":         13,
+		"[0.0s–5.0s] (A)":             14,
+		"[0.0s–5.0s] (A) ":            17,
+		"[1e3s–5.0s] (A) a.wav":       2,
+		"[00:61.0–01:05.0] (A) a.wav": 2,
+	}
+
+	for line, column := range testCases {
+		_, err := parseTextLine(line, FrameRate{})
+		if err == nil {
+			t.Errorf("parseTextLine(%q): expected an error, but got nil", line)
+			continue
+		}
+		if err.Column != column {
+			t.Errorf("parseTextLine(%q): expected an error at column %d, got %d (%v)", line, column, err.Column, err)
+		}
+	}
+}
+
+func TestParseTextLine_TimeErrorQuotesToken(t *testing.T) {
+	testCases := map[string]string{
+		"[1e3s–5.0s] (A) a.wav":       `"1e3s"`,
+		"[0.0s–5.0xs] (A) a.wav":      `"5.0xs"`,
+		"[00:61.0–01:05.0] (A) a.wav": `"00:61.0"`,
+	}
+
+	for line, quoted := range testCases {
+		_, err := parseTextLine(line, FrameRate{})
+		if err == nil {
+			t.Errorf("parseTextLine(%q): expected an error, but got nil", line)
+			continue
+		}
+		if msg := err.Error(); !strings.Contains(msg, quoted) || !strings.Contains(msg, textTimeForms) {
+			t.Errorf("parseTextLine(%q): expected an error quoting %s and the accepted forms, got %q", line, quoted, msg)
+		}
+	}
+}
+
+func TestWrite_TextTimeFormats(t *testing.T) {
+	testCases := map[TimeFormat]string{
+		"":               "[62.5s–3725.25s] (A) a.wav\n",
+		TimeSeconds:      "[62.5s–3725.25s] (A) a.wav\n",
+		TimeMilliseconds: "[62500ms–3725250ms] (A) a.wav\n",
+		TimeClock:        "[00:01:02.500–01:02:05.250] (A) a.wav\n",
+	}
+
+	for format, expected := range testCases {
+		tmpDir := t.TempDir()
+		entries := []ManifestEntry{{StartTime: 62.5, EndTime: 3725.25, Speaker: "A", FilePath: filepath.Join(tmpDir, "a.wav")}}
+		manifestPath := filepath.Join(tmpDir, "manifest.txt")
+		if err := WriteWithOptions(manifestPath, entries, Options{TimeFormat: format}); err != nil {
+			t.Fatalf("WriteWithOptions(%q) error = %v", format, err)
+		}
+		data, err := os.ReadFile(manifestPath)
+		if err != nil {
+			t.Fatalf("failed to read manifest: %v", err)
+		}
+		if string(data) != expected {
+			t.Errorf("time format %q: expected %q, got %q", format, expected, data)
+		}
+	}
+}
+
+func TestText_CommentsAndDirectives(t *testing.T) {
+	content := `# Episode 1
+#! sample_rate=48000
+#!Min_Speed = 0.85
+
+# Act 1
+[0.0s–5.0s] (SPEAKER_00) audio/000.wav
+  # indented note
+
+[5.7s–8.4s] (SPEAKER_01) audio/001.wav
+# end
+`
+	manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	m, err := Load(manifestPath, Options{})
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+	expected := &Manifest{
+		Format:  FormatText,
+		BaseDir: filepath.Dir(manifestPath),
+		Directives: []Directive{
+			{Key: "sample_rate", Value: "48000", Comments: []string{"# Episode 1"}},
+			{Key: "min_speed", Value: "0.85"},
+		},
+		Entries: []ManifestEntry{
+			{StartTime: 0, EndTime: 5, Speaker: "SPEAKER_00", FilePath: filepath.Join(filepath.Dir(manifestPath), "audio", "000.wav"), Comments: []string{"", "# Act 1"}},
+			{StartTime: 5.7, EndTime: 8.4, Speaker: "SPEAKER_01", FilePath: filepath.Join(filepath.Dir(manifestPath), "audio", "001.wav"), Comments: []string{"  # indented note", ""}},
+		},
+		Comments: []string{"# end"},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("expected %+v, got %+v", expected, m)
+	}
+	if v, ok := m.Directive("min_speed"); !ok || v != "0.85" {
+		t.Errorf("Directive(min_speed) = %q, %v", v, ok)
+	}
+
+	if err := WriteManifest(manifestPath, m, Options{}); err != nil {
+		t.Fatalf("WriteManifest() error = %v", err)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	written := strings.Replace(content, "#!Min_Speed = 0.85", "#! min_speed=0.85", 1)
+	if string(data) != written {
+		t.Errorf("expected:\n%s\ngot:\n%s", written, data)
+	}
+}
+
+func TestText_DirectiveErrors(t *testing.T) {
+	testCases := map[string]string{
+		"after an entry": "[0.0s–1.0s] (A) /a.wav\n#! bed=music.wav\n",
+		"missing value":  "#! bed\n",
+		"missing key":    "#! =music.wav\n",
+		"duplicate":      "#! bed=a.wav\n#! BED=b.wav\n",
+	}
+
+	for name, content := range testCases {
+		t.Run(name, func(t *testing.T) {
+			manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+			if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			if _, err := Parse(manifestPath); err == nil {
+				t.Error("expected an error, but got nil")
+			}
+		})
+	}
+}
+
+func TestWrite_TextUnwritableEntries(t *testing.T) {
+	testCases := map[string]ManifestEntry{
+		"speaker with \") \"":       {StartTime: 0, EndTime: 1, Speaker: "John) (narrator", FilePath: "a.wav"},
+		"speaker with a newline":    {StartTime: 0, EndTime: 1, Speaker: "A\nB", FilePath: "a.wav"},
+		"path with a newline":       {StartTime: 0, EndTime: 1, Speaker: "A", FilePath: "a\n.wav"},
+		"path with a leading space": {StartTime: 0, EndTime: 1, Speaker: "A", FilePath: " a.wav"},
+	}
+
+	for name, entry := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := &Manifest{Entries: []ManifestEntry{entry}}
+			if losses := Losses(m, Options{Format: FormatText}); len(losses) != 1 {
+				t.Errorf("expected one loss, got %q", losses)
+			}
+			var buf strings.Builder
+			if err := Encode(&buf, m, Options{}); err == nil || buf.Len() > 0 {
+				t.Errorf("expected an error and no output, got %v and %q", err, buf.String())
+			}
+		})
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+	if err := WriteManifest(manifestPath, &Manifest{Entries: []ManifestEntry{testCases["speaker with a newline"]}}, Options{}); err == nil {
+		t.Error("expected an error, but got nil")
+	}
+	if _, err := os.Stat(manifestPath); !os.IsNotExist(err) {
+		t.Error("expected no manifest to be left behind")
+	}
+
+	// Parentheses that don't end the speaker are kept.
+	entry := ManifestEntry{StartTime: 0, EndTime: 1, Speaker: "John (narrator)", FilePath: "/a (2).wav"}
+	var buf strings.Builder
+	if err := Encode(&buf, &Manifest{Entries: []ManifestEntry{entry}}, Options{}); err != nil {
+		t.Fatalf("Encode() error = %v", err)
+	}
+	got, err := parseTextLine(strings.TrimSuffix(buf.String(), "\n"), FrameRate{})
+	if err != nil || !reflect.DeepEqual(got, entry) {
+		t.Errorf("expected %+v to read back, got %+v, %v", entry, got, err)
+	}
+}
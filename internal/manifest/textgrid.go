@@ -0,0 +1,256 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+// textGridPathTemplate is the default path template for TextGrid intervals:
+// the interval label is the clip path.
+const textGridPathTemplate = "{label}"
+
+// textGridToken is a value in a TextGrid text file: a number, a quoted
+// string, or a flag such as <exists>.
+type textGridToken struct {
+	line   int
+	kind   byte // 'n' number, 's' string, 'f' flag
+	number float64
+	text   string
+}
+
+// parseTextGrid reads the interval tiers of a Praat TextGrid in either the
+// long or the short text form. Each tier name is a speaker and every interval
+// with a non-blank label is an entry; blank intervals are gaps. Point tiers
+// are ignored. The clip path is built from opts.PathTemplate, which defaults
+// to the label itself and can use {label}, {tier} and {interval} (one-based
+// position in the tier) besides the common fields. Entries are ordered by
+// start time.
+func parseTextGrid(data []byte, opts Options) ([]ManifestEntry, error) {
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = textGridPathTemplate
+	}
+
+	tokens, err := tokenizeTextGrid(decodeTextGrid(data))
+	if err != nil {
+		return nil, err
+	}
+	tg := &textGridReader{tokens: tokens}
+
+	if fileType := tg.str(); tg.err == nil && fileType != "ooTextFile" {
+		return nil, fmt.Errorf("unsupported file type %q", fileType)
+	}
+	if class := tg.str(); tg.err == nil && class != "TextGrid" {
+		return nil, fmt.Errorf("unsupported object class %q", class)
+	}
+	tg.num() // xmin
+	tg.num() // xmax
+	tierCount := 0
+	if tg.flag() == "exists" {
+		tierCount = tg.count()
+	}
+
+	type interval struct {
+		tier       string
+		number     int
+		start, end float64
+		label      string
+	}
+	var intervals []interval
+	for t := 0; t < tierCount && tg.err == nil; t++ {
+		class := tg.str()
+		name := tg.str()
+		tg.num() // xmin
+		tg.num() // xmax
+		n := tg.count()
+		for i := 0; i < n && tg.err == nil; i++ {
+			if class != "IntervalTier" {
+				tg.num() // time
+				tg.str() // mark
+				continue
+			}
+			start, end, label := tg.num(), tg.num(), strings.TrimSpace(tg.str())
+			if label != "" {
+				intervals = append(intervals, interval{tier: name, number: i + 1, start: start, end: end, label: label})
+			}
+		}
+	}
+	if tg.err != nil {
+		return nil, tg.err
+	}
+
+	sort.SliceStable(intervals, func(i, j int) bool { return intervals[i].start < intervals[j].start })
+	entries := make([]ManifestEntry, 0, len(intervals))
+	for i, iv := range intervals {
+		path, err := expandTemplate(tmpl, templateFields{
+			"index":    i,
+			"number":   i + 1,
+			"speaker":  iv.tier,
+			"start":    iv.start,
+			"end":      iv.end,
+			"label":    iv.label,
+			"tier":     iv.tier,
+			"interval": iv.number,
+		})
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, ManifestEntry{
+			StartTime: iv.start,
+			EndTime:   iv.end,
+			Speaker:   iv.tier,
+			FilePath:  path,
+		})
+	}
+	return entries, nil
+}
+
+// decodeTextGrid converts UTF-16 files, which Praat writes when a label
+// isn't plain ASCII, to UTF-8.
+func decodeTextGrid(data []byte) []byte {
+	var order binary.ByteOrder
+	switch {
+	case bytes.HasPrefix(data, []byte{0xFF, 0xFE}):
+		order = binary.LittleEndian
+	case bytes.HasPrefix(data, []byte{0xFE, 0xFF}):
+		order = binary.BigEndian
+	default:
+		return data
+	}
+	units := make([]uint16, (len(data)-2)/2)
+	for i := range units {
+		units[i] = order.Uint16(data[2+2*i:])
+	}
+	return []byte(string(utf16.Decode(units)))
+}
+
+// tokenizeTextGrid extracts the values of a TextGrid. The long form labels
+// each value ("xmin = 0", "item [1]:"), while the short form lists the bare
+// values, so dropping labels and bracketed indices leaves the same sequence
+// for both. "!" starts a comment that runs to the end of the line.
+func tokenizeTextGrid(data []byte) ([]textGridToken, error) {
+	var tokens []textGridToken
+	s := string(data)
+	line := 1
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch {
+		case c == '\n':
+			line++
+			i++
+		case c == '!':
+			for i < len(s) && s[i] != '\n' {
+				i++
+			}
+		case c == '"':
+			// Quotes inside strings are doubled.
+			var b strings.Builder
+			startLine := line
+			i++
+			for {
+				if i >= len(s) {
+					return nil, errorAt(startLine, 0, "unterminated string")
+				}
+				if s[i] == '"' {
+					if i+1 < len(s) && s[i+1] == '"' {
+						b.WriteByte('"')
+						i += 2
+						continue
+					}
+					i++
+					break
+				}
+				if s[i] == '\n' {
+					line++
+				}
+				b.WriteByte(s[i])
+				i++
+			}
+			tokens = append(tokens, textGridToken{line: startLine, kind: 's', text: b.String()})
+		case c == '<':
+			end := strings.IndexByte(s[i:], '>')
+			if end < 0 {
+				return nil, errorAt(line, 0, "unterminated flag")
+			}
+			tokens = append(tokens, textGridToken{line: line, kind: 'f', text: s[i+1 : i+end]})
+			i += end + 1
+		case c == '[':
+			end := strings.IndexByte(s[i:], ']')
+			if end < 0 {
+				return nil, errorAt(line, 0, "unterminated index")
+			}
+			i += end + 1
+		case c == '-' || c == '+' || c == '.' || (c >= '0' && c <= '9'):
+			j := i + 1
+			for j < len(s) && strings.IndexByte("0123456789.eE+-", s[j]) >= 0 {
+				j++
+			}
+			v, err := strconv.ParseFloat(s[i:j], 64)
+			if err != nil {
+				return nil, errorAt(line, 0, "invalid number %q", s[i:j])
+			}
+			tokens = append(tokens, textGridToken{line: line, kind: 'n', number: v})
+			i = j
+		case c == '_' || c == '?' || (c|0x20 >= 'a' && c|0x20 <= 'z'):
+			// A label such as "xmin" or "tiers?".
+			for i < len(s) && (s[i] == '_' || s[i] == '?' || (s[i]|0x20 >= 'a' && s[i]|0x20 <= 'z')) {
+				i++
+			}
+		default:
+			// Whitespace, "=", ":" and anything else separating values.
+			i++
+		}
+	}
+	return tokens, nil
+}
+
+// textGridReader consumes tokens in order. The first mismatch is kept in err
+// and later reads return zero values, so callers can check once at the end.
+type textGridReader struct {
+	tokens []textGridToken
+	pos    int
+	err    error
+}
+
+func (r *textGridReader) next(kind byte, what string) textGridToken {
+	if r.err != nil {
+		return textGridToken{}
+	}
+	if r.pos >= len(r.tokens) {
+		r.err = fmt.Errorf("unexpected end of file, expected %s", what)
+		return textGridToken{}
+	}
+	tok := r.tokens[r.pos]
+	if tok.kind != kind {
+		r.err = errorAt(tok.line, 0, "expected %s", what)
+		return textGridToken{}
+	}
+	r.pos++
+	return tok
+}
+
+func (r *textGridReader) num() float64 { return r.next('n', "a number").number }
+func (r *textGridReader) str() string  { return r.next('s', "a quoted string").text }
+func (r *textGridReader) flag() string { return r.next('f', "<exists> or <absent>").text }
+
+func (r *textGridReader) count() int {
+	tok := r.next('n', "a count")
+	if r.err == nil && (tok.number < 0 || tok.number != float64(int(tok.number))) {
+		r.err = errorAt(tok.line, 0, "invalid count %v", tok.number)
+	}
+	return int(tok.number)
+}
+
+// looksLikeTextGrid reports whether data starts with a TextGrid header.
+func looksLikeTextGrid(data []byte) bool {
+	head := data
+	if len(head) > 256 {
+		head = head[:256]
+	}
+	return bytes.Contains(head, []byte(`"ooTextFile"`)) && bytes.Contains(head, []byte(`"TextGrid"`))
+}
@@ -0,0 +1,162 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"unicode/utf16"
+)
+
+const textGridLong = `File type = "ooTextFile"
+Object class = "TextGrid"
+
+xmin = 0 
+xmax = 6 
+tiers? <exists> 
+size = 3 
+item []: 
+    item [1]:
+        class = "IntervalTier" 
+        name = "ALICE" 
+        xmin = 0 
+        xmax = 6 
+        intervals: size = 3 
+        intervals [1]:
+            xmin = 0 
+            xmax = 0.5 
+            text = "" 
+        intervals [2]:
+            xmin = 0.5 
+            xmax = 2.75 
+            text = "clips/alice ""one"".wav" 
+        intervals [3]:
+            xmin = 2.75 
+            xmax = 6 
+            text = "   " 
+    item [2]:
+        class = "TextTier" 
+        name = "events" 
+        xmin = 0 
+        xmax = 6 
+        points: size = 1 
+        points [1]:
+            number = 1.5 
+            mark = "door" 
+    item [3]:
+        class = "IntervalTier" 
+        name = "BOB" 
+        xmin = 0 
+        xmax = 6 
+        intervals: size = 2 
+        intervals [1]:
+            xmin = 0 
+            xmax = 0.25 
+            text = "clips/bob.wav" 
+        intervals [2]:
+            xmin = 0.25 
+            xmax = 6 
+            text = "" 
+`
+
+const textGridShort = `File type = "ooTextFile"
+Object class = "TextGrid"
+
+0
+6
+<exists>
+3
+"IntervalTier"
+"ALICE"
+0
+6
+3
+0
+0.5
+""
+0.5
+2.75
+"clips/alice ""one"".wav"
+2.75
+6
+"   "
+"TextTier"
+"events"
+0
+6
+1
+1.5
+"door" ! a comment
+"IntervalTier"
+"BOB"
+0
+6
+2
+0
+0.25
+"clips/bob.wav"
+0.25
+6
+""
+`
+
+func TestParse_TextGrid(t *testing.T) {
+	utf16Data := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(textGridShort)) {
+		utf16Data = append(utf16Data, byte(u), byte(u>>8))
+	}
+
+	testCases := map[string][]byte{
+		"long":   []byte(textGridLong),
+		"short":  []byte(textGridShort),
+		"utf-16": utf16Data,
+	}
+	for name, data := range testCases {
+		t.Run(name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			manifestPath := filepath.Join(tmpDir, "episode.TextGrid")
+			if err := os.WriteFile(manifestPath, data, 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			entries, err := Parse(manifestPath)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			expected := []ManifestEntry{
+				{StartTime: 0, EndTime: 0.25, Speaker: "BOB", FilePath: filepath.Join(tmpDir, "clips/bob.wav")},
+				{StartTime: 0.5, EndTime: 2.75, Speaker: "ALICE", FilePath: filepath.Join(tmpDir, `clips/alice "one".wav`)},
+			}
+			if !reflect.DeepEqual(entries, expected) {
+				t.Errorf("expected %+v, got %+v", expected, entries)
+			}
+		})
+	}
+}
+
+func TestParse_TextGrid_PathTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "alignment")
+	if err := os.WriteFile(manifestPath, []byte(textGridShort), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "{tier}/{interval:02}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if len(entries) != 2 || entries[0].FilePath != filepath.Join(tmpDir, "BOB/01.wav") || entries[1].FilePath != filepath.Join(tmpDir, "ALICE/02.wav") {
+		t.Errorf("unexpected entries %+v", entries)
+	}
+}
+
+func TestParse_TextGrid_Truncated(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "episode.TextGrid")
+	if err := os.WriteFile(manifestPath, []byte(textGridShort[:len(textGridShort)/2]), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for a truncated TextGrid, but got nil")
+	}
+}
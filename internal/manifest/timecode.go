@@ -0,0 +1,173 @@
+package manifest
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// FrameRate is a video frame rate, stored as an exact fraction so that NTSC
+// rates such as 30000/1001 don't drift. The zero value means no frame rate.
+type FrameRate struct {
+	Num, Den int64
+	// DropFrame selects drop-frame timecode, which skips frame numbers to
+	// keep 29.97 and 59.94 fps timecode in step with the clock.
+	DropFrame bool
+}
+
+// frameRates are the supported rates by name. "df" and "ndf" select drop-frame
+// and non-drop-frame timecode for the NTSC rates; NDF is the default.
+var frameRates = map[string]FrameRate{
+	"23.976":   {Num: 24000, Den: 1001},
+	"23.98":    {Num: 24000, Den: 1001},
+	"24":       {Num: 24, Den: 1},
+	"25":       {Num: 25, Den: 1},
+	"29.97":    {Num: 30000, Den: 1001},
+	"29.97ndf": {Num: 30000, Den: 1001},
+	"29.97df":  {Num: 30000, Den: 1001, DropFrame: true},
+	"30":       {Num: 30, Den: 1},
+	"50":       {Num: 50, Den: 1},
+	"59.94":    {Num: 60000, Den: 1001},
+	"59.94ndf": {Num: 60000, Den: 1001},
+	"59.94df":  {Num: 60000, Den: 1001, DropFrame: true},
+}
+
+// ParseFrameRate parses a frame rate such as "25", "23.976" or "29.97df".
+func ParseFrameRate(s string) (FrameRate, error) {
+	key := strings.ToLower(strings.Join(strings.Fields(s), ""))
+	rate, ok := frameRates[key]
+	if !ok {
+		return FrameRate{}, fmt.Errorf("unsupported frame rate %q (expected 23.976, 24, 25, 29.97, 29.97df, 30, 50, 59.94 or 59.94df)", s)
+	}
+	return rate, nil
+}
+
+// IsZero reports whether no frame rate is set.
+func (r FrameRate) IsZero() bool {
+	return r.Num == 0 || r.Den == 0
+}
+
+// String returns the rate in the form accepted by ParseFrameRate.
+func (r FrameRate) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	// NTSC rates are conventionally truncated, e.g. 29.97002997 to 29.97.
+	s := strconv.FormatFloat(math.Floor(float64(r.Num)/float64(r.Den)*1000)/1000, 'f', -1, 64)
+	if r.DropFrame {
+		s += "df"
+	}
+	return s
+}
+
+// Set parses s into r, so a FrameRate can be used as a command-line flag.
+func (r *FrameRate) Set(s string) error {
+	rate, err := ParseFrameRate(s)
+	if err != nil {
+		return err
+	}
+	*r = rate
+	return nil
+}
+
+// Type names the flag value type.
+func (r *FrameRate) Type() string {
+	return "fps"
+}
+
+// nominal is the whole number of frames counted per timecode second.
+func (r FrameRate) nominal() int64 {
+	return (r.Num + r.Den/2) / r.Den
+}
+
+// dropped is the number of frame numbers skipped at the start of every minute
+// except each tenth, in drop-frame timecode.
+func (r FrameRate) dropped() int64 {
+	if !r.DropFrame {
+		return 0
+	}
+	return r.nominal() / 15
+}
+
+// FrameSeconds returns the time at which frame n starts.
+func (r FrameRate) FrameSeconds(n int64) float64 {
+	return float64(n) * float64(r.Den) / float64(r.Num)
+}
+
+// NearestFrame returns the frame whose start is closest to seconds.
+func (r FrameRate) NearestFrame(seconds float64) int64 {
+	return int64(math.Round(seconds * float64(r.Num) / float64(r.Den)))
+}
+
+// Snap moves seconds to the start of the nearest frame.
+func (r FrameRate) Snap(seconds float64) float64 {
+	return r.FrameSeconds(r.NearestFrame(seconds))
+}
+
+// FrameAt reports the frame starting at seconds, if seconds falls on a frame
+// boundary to within a microsecond.
+func (r FrameRate) FrameAt(seconds float64) (int64, bool) {
+	n := r.NearestFrame(seconds)
+	return n, math.Abs(r.FrameSeconds(n)-seconds) < 1e-6
+}
+
+// SampleOffset returns the sample frame nearest to the start of video frame
+// n at the given sample rate, computed exactly in integers.
+func (r FrameRate) SampleOffset(n int64, sampleRate int) int64 {
+	num := n * int64(sampleRate) * r.Den
+	return (2*num + r.Num) / (2 * r.Num)
+}
+
+// timecodeRe matches HH:MM:SS:FF, or HH:MM:SS;FF as written for drop-frame
+// timecode.
+var timecodeRe = regexp.MustCompile(`^(\d{2}):(\d{2}):(\d{2})[:;](\d{2})$`)
+
+// ParseTimecode converts an SMPTE timecode to a frame count.
+func (r FrameRate) ParseTimecode(tc string) (int64, error) {
+	if r.IsZero() {
+		return 0, fmt.Errorf("timecode %q needs a frame rate", tc)
+	}
+	m := timecodeRe.FindStringSubmatch(tc)
+	if m == nil {
+		return 0, fmt.Errorf("invalid timecode %q", tc)
+	}
+	var parts [4]int64
+	for i := range parts {
+		parts[i], _ = strconv.ParseInt(m[i+1], 10, 64)
+	}
+	h, min, s, f := parts[0], parts[1], parts[2], parts[3]
+
+	nominal := r.nominal()
+	if min > 59 || s > 59 || f >= nominal {
+		return 0, fmt.Errorf("invalid timecode %q at %s fps", tc, r)
+	}
+	drop := r.dropped()
+	if drop > 0 && s == 0 && min%10 != 0 && f < drop {
+		return 0, fmt.Errorf("timecode %q does not exist in drop-frame timecode", tc)
+	}
+
+	totalMinutes := h*60 + min
+	return ((h*3600+min*60+s)*nominal + f) - drop*(totalMinutes-totalMinutes/10), nil
+}
+
+// FormatTimecode renders frame n as an SMPTE timecode. Drop-frame timecode
+// uses ";" before the frames.
+func (r FrameRate) FormatTimecode(n int64) string {
+	nominal := r.nominal()
+	sep := ":"
+	if drop := r.dropped(); drop > 0 {
+		sep = ";"
+		perMinute := nominal*60 - drop
+		perTenMinutes := perMinute*10 + drop
+		tens, rem := n/perTenMinutes, n%perTenMinutes
+		n += drop * 9 * tens
+		if rem >= drop {
+			n += drop * ((rem - drop) / perMinute)
+		}
+	}
+	f := n % nominal
+	s := n / nominal
+	return fmt.Sprintf("%02d:%02d:%02d%s%02d", s/3600, s/60%60, s%60, sep, f)
+}
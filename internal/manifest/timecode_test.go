@@ -0,0 +1,155 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFrameRate_Timecode(t *testing.T) {
+	testCases := []struct {
+		rate  string
+		tc    string
+		frame int64
+	}{
+		{"25", "00:01:02:12", (60+2)*25 + 12},
+		{"24", "01:00:00:00", 86400},
+		{"23.976", "00:00:01:00", 24},
+		{"29.97", "00:01:00:00", 1800},
+		{"29.97df", "00:00:59;29", 1799},
+		{"29.97df", "00:01:00;02", 1800},
+		{"29.97df", "00:10:00;00", 17982},
+		{"29.97df", "01:00:00;00", 107892},
+		{"59.94df", "00:01:00;04", 3600},
+		{"50", "00:00:00:49", 49},
+	}
+
+	for _, tc := range testCases {
+		rate, err := ParseFrameRate(tc.rate)
+		if err != nil {
+			t.Fatalf("ParseFrameRate(%q) error = %v", tc.rate, err)
+		}
+		frame, err := rate.ParseTimecode(tc.tc)
+		if err != nil {
+			t.Errorf("%s: ParseTimecode(%q) error = %v", tc.rate, tc.tc, err)
+			continue
+		}
+		if frame != tc.frame {
+			t.Errorf("%s: ParseTimecode(%q) = %d, want %d", tc.rate, tc.tc, frame, tc.frame)
+		}
+		if got := rate.FormatTimecode(tc.frame); got != tc.tc {
+			t.Errorf("%s: FormatTimecode(%d) = %q, want %q", tc.rate, tc.frame, got, tc.tc)
+		}
+	}
+}
+
+func TestFrameRate_DropFrameRoundTrip(t *testing.T) {
+	rate, _ := ParseFrameRate("29.97df")
+	for n := int64(0); n < 20*17982; n += 7 {
+		frame, err := rate.ParseTimecode(rate.FormatTimecode(n))
+		if err != nil || frame != n {
+			t.Fatalf("frame %d: round trip through %q gave %d, %v", n, rate.FormatTimecode(n), frame, err)
+		}
+	}
+}
+
+func TestFrameRate_InvalidTimecode(t *testing.T) {
+	df, _ := ParseFrameRate("29.97df")
+	pal, _ := ParseFrameRate("25")
+	testCases := []struct {
+		rate FrameRate
+		tc   string
+	}{
+		{df, "00:01:00;00"},
+		{df, "00:01:00;01"},
+		{pal, "00:00:00:25"},
+		{pal, "00:60:00:00"},
+		{pal, "0:00:00:00"},
+		{FrameRate{}, "00:00:01:00"},
+	}
+	for _, tc := range testCases {
+		if _, err := tc.rate.ParseTimecode(tc.tc); err == nil {
+			t.Errorf("%s: expected an error for %q, but got nil", tc.rate, tc.tc)
+		}
+	}
+
+	if _, err := ParseFrameRate("29"); err == nil {
+		t.Error("expected an error for an unsupported frame rate, but got nil")
+	}
+}
+
+func TestFrameRate_SampleOffset(t *testing.T) {
+	rate, _ := ParseFrameRate("23.976")
+	// Frame 40 starts exactly halfway between samples 73573 and 73574.
+	if got := rate.SampleOffset(40, 44100); got != 73574 {
+		t.Errorf("SampleOffset(40, 44100) = %d, want 73574", got)
+	}
+	ntsc, _ := ParseFrameRate("29.97")
+	if got := ntsc.SampleOffset(30000, 48000); got != 48048000 {
+		t.Errorf("SampleOffset(30000, 48000) = %d, want 48048000", got)
+	}
+}
+
+func TestParse_TextTimecode(t *testing.T) {
+	content := "[00:01:02:12–00:01:05:03] (SPEAKER_00) /audio/000.wav\n" +
+		"[65.5s–00:01:07:00] (SPEAKER_01) /audio/001.wav\n"
+	manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for timecode without a frame rate, but got nil")
+	}
+
+	rate, _ := ParseFrameRate("25")
+	entries, err := ParseWithOptions(manifestPath, Options{FrameRate: rate})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if entries[0].StartTime != 62.48 || entries[0].EndTime != 65.12 || entries[1].StartTime != 65.5 || entries[1].EndTime != 67 {
+		t.Errorf("unexpected times %+v", entries)
+	}
+
+	snapped, err := ParseWithOptions(manifestPath, Options{FrameRate: rate, SnapToFrames: true})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if snapped[1].StartTime != 65.52 {
+		t.Errorf("expected 65.5s to snap to 65.52s at 25 fps, got %v", snapped[1].StartTime)
+	}
+}
+
+func TestWrite_TextTimecode(t *testing.T) {
+	rate, _ := ParseFrameRate("29.97df")
+	tmpDir := t.TempDir()
+	entries := []ManifestEntry{
+		{StartTime: rate.FrameSeconds(1800), EndTime: rate.FrameSeconds(17982), Speaker: "SPEAKER_00", FilePath: filepath.Join(tmpDir, "audio", "000.wav")},
+	}
+	opts := Options{FrameRate: rate, TimeFormat: TimeTimecode}
+
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := WriteWithOptions(manifestPath, entries, opts); err != nil {
+		t.Fatalf("WriteWithOptions() error = %v", err)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	if expected := "[00:01:00;02–00:10:00;00] (SPEAKER_00) audio/000.wav\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+
+	parsed, err := ParseWithOptions(manifestPath, opts)
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+	if parsed[0].StartTime != entries[0].StartTime || parsed[0].EndTime != entries[0].EndTime {
+		t.Errorf("expected %+v, got %+v", entries[0], parsed[0])
+	}
+
+	if err := WriteWithOptions(manifestPath, entries, Options{TimeFormat: TimeTimecode}); err == nil || !strings.Contains(err.Error(), "frame rate") {
+		t.Errorf("expected a missing frame rate error, got %v", err)
+	}
+}
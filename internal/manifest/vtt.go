@@ -0,0 +1,244 @@
+package manifest
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// vttClipNote is the prefix of the NOTE comment that names a cue's clip. The
+// note must come directly before the cue it describes:
+//
+//	NOTE clip: clips/001.wav
+//
+//	00:00:01.000 --> 00:00:04.000
+//	<v SPEAKER_01>Hello there.
+const vttClipNote = "clip:"
+
+// vttTimingRe matches a WebVTT timing line, ignoring any cue settings after it.
+var vttTimingRe = regexp.MustCompile(`^\s*((?:\d+:)?\d{2}:\d{2}\.\d{3})\s+-->\s+((?:\d+:)?\d{2}:\d{2}\.\d{3})(?:\s|$)`)
+
+// vttVoiceRe matches a voice span start tag, capturing its annotation.
+var vttVoiceRe = regexp.MustCompile(`<v(?:\.[^\s>]*)?\s+([^>]*)>`)
+
+// vttTagRe matches any cue text markup tag.
+var vttTagRe = regexp.MustCompile(`<[^>]*>`)
+
+// parseVTT reads WebVTT cues. The speaker comes from the first <v> voice span
+// and the clip path from a preceding "NOTE clip:" comment. Cues without a
+// clip note get a path from opts.PathTemplate, as SubRip cues do.
+func parseVTT(data []byte, opts Options) ([]ManifestEntry, error) {
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = DefaultPathTemplate
+	}
+
+	blocks, err := splitBlocks(data)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 || !isVTTHeader(blocks[0].lines[0]) {
+		return nil, errorAt(1, 1, "missing WEBVTT header")
+	}
+
+	var entries []ManifestEntry
+	var clipPath string
+	probs := newProblems(opts)
+	for _, block := range blocks[1:] {
+		first := block.lines[0]
+		switch {
+		case strings.HasPrefix(first, "NOTE"):
+			note := strings.TrimSpace(strings.Join(block.lines, "\n")[len("NOTE"):])
+			if strings.HasPrefix(note, vttClipNote) {
+				clipPath = strings.TrimSpace(note[len(vttClipNote):])
+			}
+			continue
+		case first == "STYLE" || first == "REGION":
+			continue
+		}
+
+		// An optional cue identifier precedes the timing line.
+		timing := 0
+		var id string
+		if !vttTimingRe.MatchString(first) {
+			timing = 1
+			id = strings.TrimSpace(first)
+		}
+		var cueErr *ParseError
+		var start, end float64
+		if timing >= len(block.lines) {
+			cueErr = errorAt(block.line, 1, "expected a cue timing line, got %q", first)
+		} else if m := vttTimingRe.FindStringSubmatch(block.lines[timing]); m == nil {
+			cueErr = errorAt(block.line+timing, 1, "expected a cue timing line, got %q", block.lines[timing])
+		} else {
+			start, err = parseSRTTimestamp(m[1])
+			if err == nil {
+				end, err = parseSRTTimestamp(m[2])
+			}
+			if err != nil {
+				cueErr = errorAt(block.line+timing, 1, "%v", err)
+			}
+		}
+		if cueErr != nil {
+			// The clip note belonged to the skipped cue.
+			clipPath = ""
+			if !probs.add(cueErr) {
+				break
+			}
+			continue
+		}
+
+		payload := strings.Join(block.lines[timing+1:], "\n")
+		var speaker string
+		if voice := vttVoiceRe.FindStringSubmatch(payload); voice != nil {
+			speaker = html.UnescapeString(strings.TrimSpace(voice[1]))
+		}
+		text := html.UnescapeString(strings.TrimSpace(vttTagRe.ReplaceAllString(payload, "")))
+
+		path := clipPath
+		if path == "" {
+			path, err = expandTemplate(tmpl, templateFields{
+				"index":   len(entries),
+				"number":  len(entries) + 1,
+				"speaker": speaker,
+				"start":   start,
+				"end":     end,
+				"text":    text,
+			})
+			if err != nil {
+				return nil, err
+			}
+		}
+		clipPath = ""
+
+		entries = append(entries, ManifestEntry{
+			ID:        id,
+			StartTime: start,
+			EndTime:   end,
+			Speaker:   speaker,
+			FilePath:  path,
+			Text:      text,
+		})
+	}
+	if err := probs.err(); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// vttEscaper escapes cue text and voice annotations. Escaping ">" also keeps
+// "-->", which may not appear in a cue, out of them.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;")
+
+// writeVTT writes entries as WebVTT cues, each preceded by a note naming its
+// clip. Entry IDs become cue identifiers. Blank lines are dropped from the
+// text, since they would end the cue.
+func writeVTT(w io.Writer, entries []ManifestEntry, opts Options) error {
+	// Check every entry first, so nothing is written for a manifest that
+	// would read back differently.
+	for i, entry := range entries {
+		if !vttSpeakerWritable(entry.Speaker) {
+			return fmt.Errorf("entry %d: speaker %q can't be written in the vtt format", i+1, entry.Speaker)
+		}
+		if !vttLineWritable(entry.FilePath) {
+			return fmt.Errorf("entry %d: path %q can't be written in the vtt format", i+1, entry.FilePath)
+		}
+		if !vttLineWritable(entry.ID) {
+			return fmt.Errorf("entry %d: ID %q can't be written in the vtt format", i+1, entry.ID)
+		}
+	}
+
+	writer := bufio.NewWriter(w)
+	fmt.Fprint(writer, "WEBVTT\n")
+	for _, entry := range entries {
+		fmt.Fprintf(writer, "\nNOTE %s %s\n\n", vttClipNote, entry.FilePath)
+		if entry.ID != "" {
+			fmt.Fprintf(writer, "%s\n", entry.ID)
+		}
+		fmt.Fprintf(writer, "%s --> %s\n", formatVTTTimestamp(entry.StartTime), formatVTTTimestamp(entry.EndTime))
+
+		var lines []string
+		for _, line := range strings.Split(entry.Text, "\n") {
+			if strings.TrimSpace(line) != "" {
+				lines = append(lines, vttEscaper.Replace(line))
+			}
+		}
+		text := strings.Join(lines, "\n")
+		if entry.Speaker != "" {
+			text = fmt.Sprintf("<v %s>%s", vttEscaper.Replace(entry.Speaker), text)
+		}
+		if text != "" {
+			fmt.Fprintf(writer, "%s\n", text)
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return fmt.Errorf("failed to write to manifest: %w", err)
+	}
+	return nil
+}
+
+// vttLineWritable reports whether s, a clip path in a note or a cue
+// identifier, reads back unchanged from a WebVTT file. Neither can be
+// escaped, so s can't hold "-->" or a line break, nor start or end with a
+// space, which is trimmed.
+func vttLineWritable(s string) bool {
+	return !strings.Contains(s, "-->") && !strings.ContainsAny(s, "\r\n") && strings.TrimSpace(s) == s
+}
+
+// vttSpeakerWritable reports whether speaker reads back unchanged from a
+// voice span. Markup is escaped, but line breaks and surrounding spaces
+// are not kept.
+func vttSpeakerWritable(speaker string) bool {
+	return !strings.ContainsAny(speaker, "\r\n") && strings.TrimSpace(speaker) == speaker
+}
+
+// formatVTTTimestamp renders seconds as hh:mm:ss.ttt.
+func formatVTTTimestamp(seconds float64) string {
+	ms := int64(seconds*1000 + 0.5)
+	return fmt.Sprintf("%02d:%02d:%02d.%03d", ms/3600000, ms/60000%60, ms/1000%60, ms%1000)
+}
+
+func isVTTHeader(line string) bool {
+	return line == "WEBVTT" || strings.HasPrefix(line, "WEBVTT ") || strings.HasPrefix(line, "WEBVTT\t")
+}
+
+// looksLikeVTT reports whether data starts with a WebVTT header.
+func looksLikeVTT(data []byte) bool {
+	line, _, _ := bytes.Cut(data, []byte("\n"))
+	return isVTTHeader(strings.TrimRight(string(line), "\r"))
+}
+
+// textBlock is a run of non-blank lines.
+type textBlock struct {
+	// line is the one-based line number of the block's first line.
+	line  int
+	lines []string
+}
+
+// splitBlocks splits data into blocks separated by blank lines.
+func splitBlocks(data []byte) ([]textBlock, error) {
+	var blocks []textBlock
+	var current *textBlock
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			current = nil
+			continue
+		}
+		if current == nil {
+			blocks = append(blocks, textBlock{line: lineNo})
+			current = &blocks[len(blocks)-1]
+		}
+		current.lines = append(current.lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest file: %w", err)
+	}
+	return blocks, nil
+}
@@ -0,0 +1,143 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse_VTT(t *testing.T) {
+	content := `WEBVTT - dubbing track
+
+STYLE
+::cue { color: yellow }
+
+NOTE clip: /audio/000.wav
+
+intro
+00:00:01.000 --> 00:00:04.250 align:start position:10%
+<v.loud SPEAKER_01>Hello <i>there</i>.</v>
+
+NOTE reviewed by the web team
+
+01:02.500 --> 01:05.000
+<v Mary Jane>No clip note here.
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "episode.vtt")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "clips/{index:03}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{ID: "intro", StartTime: 1.0, EndTime: 4.25, Speaker: "SPEAKER_01", FilePath: "/audio/000.wav", Text: "Hello there."},
+		{StartTime: 62.5, EndTime: 65.0, Speaker: "Mary Jane", FilePath: filepath.Join(tmpDir, "clips/001.wav"), Text: "No clip note here."},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestWrite_VTT_RoundTrip(t *testing.T) {
+	entries := []ManifestEntry{
+		{ID: "cue-1", StartTime: 0.0, EndTime: 5.0, Speaker: "SPEAKER_00", FilePath: "/path/to/audio/000.wav", Text: "First line.\nSecond line."},
+		{StartTime: 3605.75, EndTime: 3608.4, Speaker: "SPEAKER_01", FilePath: "/path/to/my clips/001.wav"},
+		{StartTime: 3609.0, EndTime: 3610.0, FilePath: "/path/to/audio/002.wav", Text: "No speaker."},
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.vtt")
+	if err := Write(manifestPath, entries); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+
+	parsed, err := Parse(manifestPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if len(parsed) != len(entries) {
+		t.Fatalf("expected %d entries, got %d", len(entries), len(parsed))
+	}
+	for i := range entries {
+		if !reflect.DeepEqual(parsed[i], entries[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, entries[i], parsed[i])
+		}
+	}
+}
+
+func TestParse_VTT_MissingHeader(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "episode.vtt")
+	if err := os.WriteFile(manifestPath, []byte("00:00:01.000 --> 00:00:02.000\nhi\n"), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for a missing WEBVTT header, but got nil")
+	}
+}
+
+func TestWrite_VTT_Escapes(t *testing.T) {
+	entries := []ManifestEntry{
+		{StartTime: 0, EndTime: 1, Speaker: "Tom & <Jerry>", FilePath: "/audio/a&b <1>.wav", Text: "a < b && c > d"},
+		{StartTime: 1, EndTime: 2, Speaker: "A", FilePath: "/audio/b.wav", Text: "fade --> out &amp; <i>not markup</i>"},
+	}
+
+	manifestPath := filepath.Join(t.TempDir(), "manifest.vtt")
+	if err := Write(manifestPath, entries); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		t.Fatalf("failed to read manifest: %v", err)
+	}
+	for _, cue := range strings.Split(string(data), "\n\n")[1:] {
+		if strings.HasPrefix(cue, "NOTE") {
+			continue
+		}
+		_, payload, _ := strings.Cut(cue, "\n")
+		if strings.Contains(payload, "-->") {
+			t.Errorf("expected no \"-->\" in cue text, got %q", payload)
+		}
+	}
+
+	parsed, err := Parse(manifestPath)
+	if err != nil {
+		t.Fatalf("Parse() error = %v", err)
+	}
+	if !reflect.DeepEqual(parsed, entries) {
+		t.Errorf("expected %+v, got %+v", entries, parsed)
+	}
+}
+
+func TestWrite_VTT_UnwritableEntries(t *testing.T) {
+	testCases := map[string]ManifestEntry{
+		"path with \"-->\"":    {StartTime: 0, EndTime: 1, FilePath: "/audio/a-->b.wav"},
+		"path with a newline":  {StartTime: 0, EndTime: 1, FilePath: "/audio/a\n\nb.wav"},
+		"ID with \"-->\"":      {StartTime: 0, EndTime: 1, FilePath: "/audio/a.wav", ID: "1 --> 2"},
+		"speaker with newline": {StartTime: 0, EndTime: 1, FilePath: "/audio/a.wav", Speaker: "A\nB"},
+	}
+
+	for name, entry := range testCases {
+		t.Run(name, func(t *testing.T) {
+			m := &Manifest{Entries: []ManifestEntry{entry}}
+			if losses := Losses(m, Options{Format: FormatVTT}); len(losses) != 1 {
+				t.Errorf("expected one loss, got %q", losses)
+			}
+			var buf strings.Builder
+			if err := Encode(&buf, m, Options{Format: FormatVTT}); err == nil || buf.Len() > 0 {
+				t.Errorf("expected an error and no output, got %v and %q", err, buf.String())
+			}
+		})
+	}
+}
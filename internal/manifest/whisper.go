@@ -0,0 +1,115 @@
+package manifest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// whisperTranscript is the part of a Whisper or WhisperX JSON transcript
+// that maps to manifest entries.
+type whisperTranscript struct {
+	Segments []whisperSegment `json:"segments"`
+}
+
+type whisperSegment struct {
+	ID      *int          `json:"id"`
+	Start   *float64      `json:"start"`
+	End     *float64      `json:"end"`
+	Text    string        `json:"text"`
+	Speaker string        `json:"speaker"`
+	Words   []whisperWord `json:"words"`
+}
+
+// whisperWord covers both dialects: WhisperX reports a "score" and may omit
+// the timings of words it couldn't align, while Whisper reports a "probability".
+type whisperWord struct {
+	Word        string   `json:"word"`
+	Start       *float64 `json:"start"`
+	End         *float64 `json:"end"`
+	Score       *float64 `json:"score"`
+	Probability *float64 `json:"probability"`
+}
+
+// parseWhisper reads the segments of a Whisper or WhisperX transcript. Each
+// segment becomes an entry carrying its text and word timings; words without
+// timings are left out of Words but remain in Text. Clip paths are built from
+// opts.PathTemplate, which can use {text} and {id} (the segment id, or the
+// index if the transcript has none) besides the common fields.
+func parseWhisper(data []byte, opts Options) ([]ManifestEntry, error) {
+	tmpl := opts.PathTemplate
+	if tmpl == "" {
+		tmpl = DefaultPathTemplate
+	}
+
+	var transcript whisperTranscript
+	if err := json.Unmarshal(data, &transcript); err != nil {
+		return nil, jsonError(data, "invalid JSON", err)
+	}
+
+	entries := make([]ManifestEntry, 0, len(transcript.Segments))
+	for i, seg := range transcript.Segments {
+		if seg.Start == nil || seg.End == nil {
+			return nil, fmt.Errorf("segment %d: missing start or end time", i)
+		}
+		id := i
+		if seg.ID != nil {
+			id = *seg.ID
+		}
+		text := strings.TrimSpace(seg.Text)
+
+		path, err := expandTemplate(tmpl, templateFields{
+			"index":   i,
+			"number":  i + 1,
+			"id":      id,
+			"speaker": seg.Speaker,
+			"start":   *seg.Start,
+			"end":     *seg.End,
+			"text":    text,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		entry := ManifestEntry{
+			StartTime: *seg.Start,
+			EndTime:   *seg.End,
+			Speaker:   seg.Speaker,
+			FilePath:  path,
+			Text:      text,
+		}
+		if seg.ID != nil {
+			entry.ID = strconv.Itoa(*seg.ID)
+		}
+		for _, w := range seg.Words {
+			if w.Start == nil || w.End == nil {
+				continue
+			}
+			word := Word{Text: strings.TrimSpace(w.Word), Start: *w.Start, End: *w.End}
+			if w.Score != nil {
+				word.Score = *w.Score
+			} else if w.Probability != nil {
+				word.Score = *w.Probability
+			}
+			entry.Words = append(entry.Words, word)
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// looksLikeWhisper reports whether data is a JSON object with a "segments" array.
+func looksLikeWhisper(data []byte) bool {
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 || trimmed[0] != '{' {
+		return false
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(trimmed, &fields) != nil {
+		return false
+	}
+	segments := bytes.TrimSpace(fields["segments"])
+	return len(segments) > 0 && segments[0] == '['
+}
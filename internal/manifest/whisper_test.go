@@ -0,0 +1,91 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestParse_WhisperX(t *testing.T) {
+	content := `{
+  "segments": [
+    {
+      "start": 0.5, "end": 2.25, "text": " Hello there.", "speaker": "SPEAKER_00",
+      "words": [
+        {"word": "Hello", "start": 0.5, "end": 0.9, "score": 0.95, "speaker": "SPEAKER_00"},
+        {"word": "there.", "start": 1.0, "end": 2.25, "score": 0.5}
+      ]
+    },
+    {
+      "start": 3.0, "end": 4.0, "text": " It's 1999.",
+      "words": [{"word": "It's", "start": 3.0, "end": 3.4, "score": 0.9}, {"word": "1999."}]
+    }
+  ],
+  "word_segments": [],
+  "language": "en"
+}`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "episode.json")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "clips/{index:03}_{speaker}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := []ManifestEntry{
+		{
+			StartTime: 0.5, EndTime: 2.25, Speaker: "SPEAKER_00", FilePath: filepath.Join(tmpDir, "clips/000_SPEAKER_00.wav"), Text: "Hello there.",
+			Words: []Word{{Text: "Hello", Start: 0.5, End: 0.9, Score: 0.95}, {Text: "there.", Start: 1.0, End: 2.25, Score: 0.5}},
+		},
+		{
+			StartTime: 3.0, EndTime: 4.0, FilePath: filepath.Join(tmpDir, "clips/001_.wav"), Text: "It's 1999.",
+			Words: []Word{{Text: "It's", Start: 3.0, End: 3.4, Score: 0.9}},
+		},
+	}
+	if len(entries) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(entries))
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(entries[i], expected[i]) {
+			t.Errorf("entry %d: expected %+v, got %+v", i, expected[i], entries[i])
+		}
+	}
+}
+
+func TestParse_Whisper(t *testing.T) {
+	content := `{"text": " Hi.", "segments": [{"id": 7, "seek": 0, "start": 0.0, "end": 1.5, "text": " Hi.",
+  "words": [{"word": " Hi.", "start": 0.0, "end": 1.5, "probability": 0.75}]}], "language": "en"}`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "transcript")
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	entries, err := ParseWithOptions(manifestPath, Options{PathTemplate: "seg_{id}.wav"})
+	if err != nil {
+		t.Fatalf("ParseWithOptions() error = %v", err)
+	}
+
+	expected := ManifestEntry{
+		ID: "7", StartTime: 0, EndTime: 1.5, FilePath: filepath.Join(tmpDir, "seg_7.wav"), Text: "Hi.",
+		Words: []Word{{Text: "Hi.", Start: 0, End: 1.5, Score: 0.75}},
+	}
+	if len(entries) != 1 || !reflect.DeepEqual(entries[0], expected) {
+		t.Errorf("expected [%+v], got %+v", expected, entries)
+	}
+}
+
+func TestParse_Whisper_MissingTimes(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "episode.json")
+	if err := os.WriteFile(manifestPath, []byte(`{"segments": [{"start": 1.0, "text": "hi"}]}`), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if _, err := Parse(manifestPath); err == nil {
+		t.Error("expected an error for a segment without an end time, but got nil")
+	}
+}
@@ -0,0 +1,66 @@
+package core
+
+import (
+	"context"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+)
+
+// The helpers below run a single audio operation under the processor's
+// per-operation timeout. Backends that accept a context are cancelled
+// mid-operation; others are only prevented from starting once ctx is done.
+
+func (p *Processor) operationContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	if p.timeout > 0 {
+		return context.WithTimeout(ctx, p.timeout)
+	}
+	return context.WithCancel(ctx)
+}
+
+func (p *Processor) getDuration(ctx context.Context, filePath string) (float64, error) {
+	ctx, cancel := p.operationContext(ctx)
+	defer cancel()
+	if cp, ok := p.audioProc.(audio.ContextProcessor); ok {
+		return cp.GetDurationContext(ctx, filePath)
+	}
+	if err := ctx.Err(); err != nil {
+		return 0, err
+	}
+	return p.audioProc.GetDuration(filePath)
+}
+
+func (p *Processor) applySpeed(ctx context.Context, inputFile, outputFile string, speed float64) error {
+	ctx, cancel := p.operationContext(ctx)
+	defer cancel()
+	if cp, ok := p.audioProc.(audio.ContextProcessor); ok {
+		return cp.ApplySpeedContext(ctx, inputFile, outputFile, speed)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.audioProc.ApplySpeed(inputFile, outputFile, speed)
+}
+
+func (p *Processor) getFormat(ctx context.Context, renderer audio.Renderer, filePath string) (audio.Format, error) {
+	ctx, cancel := p.operationContext(ctx)
+	defer cancel()
+	if cr, ok := renderer.(audio.ContextRenderer); ok {
+		return cr.GetFormatContext(ctx, filePath)
+	}
+	if err := ctx.Err(); err != nil {
+		return audio.Format{}, err
+	}
+	return renderer.GetFormat(filePath)
+}
+
+func (p *Processor) render(ctx context.Context, renderer audio.Renderer, tl audio.Timeline, outputFile string) error {
+	ctx, cancel := p.operationContext(ctx)
+	defer cancel()
+	if cr, ok := renderer.(audio.ContextRenderer); ok {
+		return cr.RenderContext(ctx, tl, outputFile)
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return renderer.Render(tl, outputFile)
+}
@@ -7,4 +7,9 @@ var (
 	ErrInvalidManifest = errors.New("invalid manifest")
 	// ErrProcessingEntry is returned when an error occurs while processing a manifest entry.
 	ErrProcessingEntry = errors.New("processing entry failed")
+	// ErrOverflow is returned when a clip overflows into the next under the fail overflow policy.
+	ErrOverflow = errors.New("clip overflows into the next")
+	// ErrCannotResample is returned when clips would need resampling to the
+	// output rate and the audio backend can't do it.
+	ErrCannotResample = errors.New("audio backend cannot resample")
 )
@@ -0,0 +1,50 @@
+package core
+
+import (
+	"fmt"
+	"strings"
+)
+
+// OverflowPolicy says what BuildFromManifest does when a clip is still
+// playing at the start of the next one, e.g. because clamping its speed
+// couldn't make it fit its slot.
+type OverflowPolicy string
+
+const (
+	// OverflowPush starts the next clip right after the previous one if it
+	// is still playing, and later clips as soon as they can. Later clips are
+	// back on time after the next long enough gap. This is how clips were
+	// always placed, and the default.
+	OverflowPush OverflowPolicy = "push"
+	// OverflowBorrowGap lets a clip run on into the silence after its slot,
+	// and each following gap takes as much of the overrun as it can, so no
+	// clip starts later than with OverflowPush. The build log says how much
+	// of each gap was borrowed.
+	OverflowBorrowGap OverflowPolicy = "borrow-gap"
+	// OverflowOverlap keeps every clip at its start time and mixes the
+	// overlap.
+	OverflowOverlap OverflowPolicy = "overlap"
+	// OverflowTruncate cuts the previous clip, with a short fade-out, where
+	// the next one starts.
+	OverflowTruncate OverflowPolicy = "truncate"
+	// OverflowFail stops the build with ErrOverflow.
+	OverflowFail OverflowPolicy = "fail"
+)
+
+// truncateFadeOut is the length of the fade-out of a truncated clip, in
+// seconds.
+const truncateFadeOut = 0.02
+
+var overflowPolicies = []OverflowPolicy{OverflowPush, OverflowBorrowGap, OverflowOverlap, OverflowTruncate, OverflowFail}
+
+// ParseOverflowPolicy returns the overflow policy named s.
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	names := make([]string, len(overflowPolicies))
+	for i, policy := range overflowPolicies {
+		if OverflowPolicy(s) == policy {
+			return policy, nil
+		}
+		names[i] = string(policy)
+	}
+	return "", fmt.Errorf("unknown overflow policy %q (expected %s)", s, strings.Join(names, ", "))
+}
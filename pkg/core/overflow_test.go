@@ -0,0 +1,161 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+)
+
+func TestProcessor_BuildFromManifest_Overflow(t *testing.T) {
+	// The first clip runs 0.3s past its slot, 0.2s further than the gap
+	// after it. The 0.1s gap before the third clip takes 0.1s of what is
+	// left, and the long gap before the last one takes the rest, so it is
+	// back on time. The mock renders at 1000 Hz.
+	manifestContent := "[0.1s–1.0s] (A) /fake/a.wav\n[1.1s–2.0s] (B) /fake/b.wav\n[2.1s–2.5s] (A) /fake/c.wav\n[4.0s–4.5s] (B) /fake/d.wav\n"
+	durations := map[string]float64{"/fake/a.wav": 1.2, "/fake/b.wav": 0.9, "/fake/c.wav": 0.4, "/fake/d.wav": 0.5}
+
+	testCases := []struct {
+		policy   OverflowPolicy
+		clips    []audio.Clip
+		frames   int64
+		overflow []string
+		lateMs   []float64
+		// borrowed lists what the build log should say was borrowed.
+		borrowed []string
+	}{
+		{
+			policy:   OverflowPush,
+			clips:    []audio.Clip{{File: "/fake/a.wav", Offset: 100}, {File: "/fake/b.wav", Offset: 1300}, {File: "/fake/c.wav", Offset: 2200}, {File: "/fake/d.wav", Offset: 4000}},
+			frames:   4500,
+			overflow: []string{"", "pushed", "pushed", ""},
+			lateMs:   []float64{0, 200, 100, 0},
+		},
+		{
+			policy:   OverflowBorrowGap,
+			clips:    []audio.Clip{{File: "/fake/a.wav", Offset: 100}, {File: "/fake/b.wav", Offset: 1300}, {File: "/fake/c.wav", Offset: 2200}, {File: "/fake/d.wav", Offset: 4000}},
+			frames:   4500,
+			overflow: []string{"", "pushed", "pushed", ""},
+			lateMs:   []float64{0, 200, 100, 0},
+			borrowed: []string{"borrowing 0.100s of the 0.100s gap", "borrowing 0.100s of the 0.100s gap", "borrowing 0.100s of the 1.500s gap"},
+		},
+		{
+			policy:   OverflowOverlap,
+			clips:    []audio.Clip{{File: "/fake/a.wav", Offset: 100}, {File: "/fake/b.wav", Offset: 1100}, {File: "/fake/c.wav", Offset: 2100}, {File: "/fake/d.wav", Offset: 4000}},
+			frames:   4500,
+			overflow: []string{"", "overlapped", "", ""},
+			lateMs:   []float64{0, 0, 0, 0},
+		},
+		{
+			policy:   OverflowTruncate,
+			clips:    []audio.Clip{{File: "/fake/a.wav", Offset: 100, Frames: 1000, FadeOut: 20}, {File: "/fake/b.wav", Offset: 1100}, {File: "/fake/c.wav", Offset: 2100}, {File: "/fake/d.wav", Offset: 4000}},
+			frames:   4500,
+			overflow: []string{"truncated", "", "", ""},
+			lateMs:   []float64{0, 0, 0, 0},
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(string(tc.policy), func(t *testing.T) {
+			var rendered audio.Timeline
+			mockAudioProc := &MockAudioProcessor{
+				GetDurationFunc: func(filePath string) (float64, error) {
+					return durations[filePath], nil
+				},
+				RenderFunc: func(tl audio.Timeline, outputFile string) error {
+					rendered = tl
+					return nil
+				},
+			}
+			var out bytes.Buffer
+			processor := NewProcessor(mockAudioProc, WithOverflow(tc.policy), WithOutput(&out))
+
+			tmpDir := t.TempDir()
+			manifestPath := filepath.Join(tmpDir, "manifest.txt")
+			if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			report, err := processor.BuildFromManifestReport(context.Background(), manifestPath, filepath.Join(tmpDir, "out.wav"))
+			if err != nil {
+				t.Fatalf("BuildFromManifestReport() error = %v", err)
+			}
+			if !reflect.DeepEqual(rendered.Clips, tc.clips) {
+				t.Errorf("expected clips %+v, got %+v", tc.clips, rendered.Clips)
+			}
+			if rendered.Frames != tc.frames {
+				t.Errorf("expected %d frames, got %d", tc.frames, rendered.Frames)
+			}
+			for i, e := range report.Entries {
+				if *e.RealizedStart < e.Start || e.ResidualMs < 0 {
+					t.Errorf("entry %d: starts at %.3fs, before its start time %.3fs", i+1, *e.RealizedStart, e.Start)
+				}
+				if math.Abs(e.ResidualMs-tc.lateMs[i]) > 1e-6 {
+					t.Errorf("entry %d: expected to start %.0fms late, got %.3fms", i+1, tc.lateMs[i], e.ResidualMs)
+				}
+				if e.Overflow != tc.overflow[i] {
+					t.Errorf("entry %d: expected overflow %q, got %q", i+1, tc.overflow[i], e.Overflow)
+				}
+				if e.Overflow == "pushed" {
+					// The log and the report measure lateness from the same
+					// start time.
+					if want := fmt.Sprintf("starting %.3fs late", e.ResidualMs/1000); !strings.Contains(out.String(), want) {
+						t.Errorf("entry %d: expected %q in the output, got:\n%s", i+1, want, out.String())
+					}
+				}
+			}
+			if got := strings.Count(out.String(), "borrowing"); got != len(tc.borrowed) {
+				t.Errorf("expected %d borrowed gaps in the output, got %d:\n%s", len(tc.borrowed), got, out.String())
+			}
+			for _, want := range tc.borrowed {
+				if !strings.Contains(out.String(), want) {
+					t.Errorf("expected %q in the output, got:\n%s", want, out.String())
+				}
+			}
+		})
+	}
+}
+
+func TestProcessor_BuildFromManifest_OverflowFail(t *testing.T) {
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.5, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			t.Error("expected nothing to be rendered")
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOverflow(OverflowFail), WithOutput(io.Discard))
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte("[0.0s–1.0s] (A) /fake/a.wav\n[1.2s–2.0s] (B) /fake/b.wav\n"), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav"))
+	if !errors.Is(err, ErrOverflow) {
+		t.Errorf("expected ErrOverflow, got %v", err)
+	}
+}
+
+func TestParseOverflowPolicy(t *testing.T) {
+	for _, name := range []string{"push", "borrow-gap", "overlap", "truncate", "fail"} {
+		if policy, err := ParseOverflowPolicy(name); err != nil || string(policy) != name {
+			t.Errorf("ParseOverflowPolicy(%q) = %q, %v", name, policy, err)
+		}
+	}
+	if _, err := ParseOverflowPolicy("squash"); err == nil {
+		t.Error("expected an error for an unknown policy")
+	}
+}
@@ -0,0 +1,130 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+// SpeedChange is the speed adjustment worked out for one manifest entry.
+// Durations are in seconds.
+type SpeedChange struct {
+	// Entry is the one-based position of the entry in the manifest.
+	Entry            int     `json:"entry"`
+	Path             string  `json:"path"`
+	ManifestDuration float64 `json:"manifest_duration"`
+	ActualDuration   float64 `json:"actual_duration"`
+	// Speed is the factor that would fit the clip exactly, and ClampedSpeed
+	// the one applied after clamping it to the allowed range.
+	Speed        float64 `json:"speed"`
+	ClampedSpeed float64 `json:"clamped_speed"`
+	// OverrunMs is how much longer than its slot the adjusted clip is, in
+	// milliseconds. It is negative for an underrun, and zero unless the speed
+	// was clamped.
+	OverrunMs float64 `json:"overrun_ms"`
+	// Error says why the entry can't be adjusted, if it can't.
+	Error string `json:"error,omitempty"`
+}
+
+// SpeedPlan lists the speed adjustments adjust-speed would make.
+type SpeedPlan struct {
+	Manifest string        `json:"manifest"`
+	Entries  []SpeedChange `json:"entries"`
+	Clamped  int           `json:"clamped"`
+	Failed   int           `json:"failed"`
+	// OverrunMs and UnderrunMs total the overruns and underruns of all
+	// entries, in milliseconds.
+	OverrunMs  float64 `json:"overrun_ms"`
+	UnderrunMs float64 `json:"underrun_ms"`
+}
+
+// PlanSpeed probes every clip in a manifest and works out the speed
+// adjustments ProcessManifest would make, without writing anything. Entries
+// that can't be adjusted are listed with their error.
+func (p *Processor) PlanSpeed(ctx context.Context, manifestPath string) (*SpeedPlan, error) {
+	m, err := manifest.Load(manifestPath, p.manifest)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+	}
+	s, err := p.manifestSettings(m)
+	if err != nil {
+		return nil, err
+	}
+
+	plan := &SpeedPlan{Manifest: manifestPath, Entries: make([]SpeedChange, len(m.Entries))}
+	p.parallel(ctx, len(m.Entries), func(i int) {
+		change, err := p.planEntry(ctx, s, m.Entries[i])
+		if err != nil {
+			change.Error = err.Error()
+		}
+		change.Entry = i + 1
+		plan.Entries[i] = change
+	})
+	if err := ctx.Err(); err != nil {
+		return nil, fmt.Errorf("planning interrupted: %w", err)
+	}
+
+	for _, change := range plan.Entries {
+		switch {
+		case change.Error != "":
+			plan.Failed++
+		case change.ClampedSpeed != change.Speed:
+			plan.Clamped++
+		}
+		if change.OverrunMs > 0 {
+			plan.OverrunMs += change.OverrunMs
+		} else {
+			plan.UnderrunMs -= change.OverrunMs
+		}
+	}
+	return plan, nil
+}
+
+// planEntry works out the speed adjustment for a single manifest entry. On
+// failure, the change holds what was worked out before it.
+func (p *Processor) planEntry(ctx context.Context, s settings, entry manifest.ManifestEntry) (SpeedChange, error) {
+	change := SpeedChange{Path: entry.FilePath, ManifestDuration: entry.EndTime - entry.StartTime}
+	if change.ManifestDuration <= 0 {
+		return change, fmt.Errorf("invalid duration in manifest (%.2fs)", change.ManifestDuration)
+	}
+
+	var err error
+	change.ActualDuration, err = p.getDuration(ctx, entry.FilePath)
+	if err != nil {
+		return change, fmt.Errorf("%w: %w", ErrProcessingEntry, err)
+	}
+	if change.ActualDuration == 0 {
+		return change, fmt.Errorf("actual duration is zero")
+	}
+
+	change.Speed = change.ActualDuration / change.ManifestDuration
+	// Clamp the speed factor to the allowed range.
+	change.ClampedSpeed = clamp(change.Speed, s.minSpeed, s.maxSpeed)
+	if change.ClampedSpeed != change.Speed {
+		change.OverrunMs = (change.ActualDuration/change.ClampedSpeed - change.ManifestDuration) * 1000
+	}
+	return change, nil
+}
+
+// WriteText writes the plan as a table, followed by a summary.
+func (plan *SpeedPlan) WriteText(w io.Writer) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	fmt.Fprintln(tw, "#\tManifest\tActual\tFactor\tClamped\tOverrun\t\tClip")
+	for _, c := range plan.Entries {
+		if c.Error != "" {
+			fmt.Fprintf(tw, "%d\t-\t-\t-\t-\t-\t\t%s: %s\n", c.Entry, c.Path, c.Error)
+			continue
+		}
+		overrun := "0ms"
+		if c.OverrunMs != 0 {
+			overrun = fmt.Sprintf("%+.0fms", c.OverrunMs)
+		}
+		fmt.Fprintf(tw, "%d\t%.3fs\t%.3fs\t%.3f\t%.3f\t%s\t\t%s\n", c.Entry, c.ManifestDuration, c.ActualDuration, c.Speed, c.ClampedSpeed, overrun, c.Path)
+	}
+	tw.Flush()
+	fmt.Fprintf(w, "%d entries, %d clamped, %d failed; total overrun %.0fms, underrun %.0fms\n",
+		len(plan.Entries), plan.Clamped, plan.Failed, plan.OverrunMs, plan.UnderrunMs)
+}
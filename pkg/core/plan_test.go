@@ -0,0 +1,84 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestProcessor_PlanSpeed(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–2.0s] (A) a.wav\n[2.0s–3.0s] (B) b.wav\n[3.0s–4.0s] (A) c.wav\n[4.0s–5.0s] (A) missing.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	durations := map[string]float64{"a.wav": 2.0, "b.wav": 1.5, "c.wav": 0.5}
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if d, ok := durations[filepath.Base(filePath)]; ok {
+				return d, nil
+			}
+			return 0, fmt.Errorf("no such file")
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			t.Errorf("ApplySpeed called for %s in a dry run", inputFile)
+			return nil
+		},
+	}
+	var out bytes.Buffer
+	processor := NewProcessor(mockAudioProc, WithOutput(&out))
+
+	plan, err := processor.PlanSpeed(context.Background(), manifestPath)
+	if err != nil {
+		t.Fatalf("PlanSpeed() error = %v", err)
+	}
+
+	expected := []SpeedChange{
+		{Entry: 1, Path: filepath.Join(tmpDir, "a.wav"), ManifestDuration: 2, ActualDuration: 2, Speed: 1, ClampedSpeed: 1},
+		{Entry: 2, Path: filepath.Join(tmpDir, "b.wav"), ManifestDuration: 1, ActualDuration: 1.5, Speed: 1.5, ClampedSpeed: maxSpeed, OverrunMs: 200},
+		{Entry: 3, Path: filepath.Join(tmpDir, "c.wav"), ManifestDuration: 1, ActualDuration: 0.5, Speed: 0.5, ClampedSpeed: minSpeed, OverrunMs: -444.444},
+	}
+	if len(plan.Entries) != 4 {
+		t.Fatalf("expected 4 entries, got %d", len(plan.Entries))
+	}
+	for i, e := range expected {
+		got := plan.Entries[i]
+		if math.Abs(got.OverrunMs-e.OverrunMs) > 0.001 {
+			t.Errorf("entry %d: expected overrun %.3fms, got %.3fms", i+1, e.OverrunMs, got.OverrunMs)
+		}
+		got.OverrunMs = e.OverrunMs
+		if got != e {
+			t.Errorf("entry %d: expected %+v, got %+v", i+1, e, got)
+		}
+	}
+	if failed := plan.Entries[3]; failed.Entry != 4 || !strings.Contains(failed.Error, "no such file") {
+		t.Errorf("expected entry 4 to fail, got %+v", failed)
+	}
+	if plan.Clamped != 2 || plan.Failed != 1 {
+		t.Errorf("expected 2 clamped and 1 failed, got %d and %d", plan.Clamped, plan.Failed)
+	}
+	if math.Abs(plan.OverrunMs-200) > 0.001 || math.Abs(plan.UnderrunMs-444.444) > 0.001 {
+		t.Errorf("expected 200ms overrun and 444ms underrun, got %.3f and %.3f", plan.OverrunMs, plan.UnderrunMs)
+	}
+
+	entries, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(entries) != 1 {
+		t.Errorf("expected a dry run to write nothing, found %d files", len(entries))
+	}
+
+	var table bytes.Buffer
+	plan.WriteText(&table)
+	if !strings.Contains(table.String(), "+200ms") || !strings.HasSuffix(table.String(), "4 entries, 2 clamped, 1 failed; total overrun 200ms, underrun 444ms\n") {
+		t.Errorf("unexpected table:\n%s", table.String())
+	}
+}
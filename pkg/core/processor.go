@@ -1,11 +1,18 @@
 package core
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"io"
 	"log"
+	"math"
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
 	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
@@ -14,186 +21,602 @@ import (
 const (
 	minSpeed = 0.9
 	maxSpeed = 1.25
-	// Epsilon is a small value to handle floating-point inaccuracies.
-	epsilon = 0.01
 )
 
 // Processor handles the core logic of processing the manifest entries.
 type Processor struct {
 	audioProc audio.Processor
+	jobs      int
+	timeout   time.Duration
+	manifest  manifest.Options
+	overflow  OverflowPolicy
+	out       io.Writer
+	outMu     sync.Mutex
+}
+
+// Option configures optional Processor settings.
+type Option func(*Processor)
+
+// WithJobs sets how many manifest entries are processed concurrently.
+// Values below one select the number of CPUs.
+func WithJobs(n int) Option {
+	return func(p *Processor) {
+		p.jobs = n
+	}
+}
+
+// WithTimeout limits how long any single audio operation may run.
+// Zero means no limit.
+func WithTimeout(d time.Duration) Option {
+	return func(p *Processor) {
+		p.timeout = d
+	}
+}
+
+// WithManifestOptions sets how manifests in formats other than the native
+// text format are mapped to entries.
+func WithManifestOptions(opts manifest.Options) Option {
+	return func(p *Processor) {
+		p.manifest = opts
+	}
+}
+
+// WithOverflow sets what BuildFromManifest does when a clip is still playing
+// at the start of the next one. Defaults to OverflowPush.
+func WithOverflow(policy OverflowPolicy) Option {
+	return func(p *Processor) {
+		p.overflow = policy
+	}
+}
+
+// WithOutput sets where progress and warnings are printed. Defaults to
+// standard output.
+func WithOutput(w io.Writer) Option {
+	return func(p *Processor) {
+		p.out = w
+	}
+}
+
+// overflowPolicy returns the overflow policy set with WithOverflow, or
+// OverflowPush.
+func (p *Processor) overflowPolicy() OverflowPolicy {
+	if p.overflow == "" {
+		return OverflowPush
+	}
+	return p.overflow
 }
 
 // NewProcessor creates a new core Processor.
-func NewProcessor(audioProc audio.Processor) *Processor {
-	return &Processor{
+func NewProcessor(audioProc audio.Processor, opts ...Option) *Processor {
+	p := &Processor{
 		audioProc: audioProc,
+		out:       os.Stdout,
+	}
+	for _, opt := range opts {
+		opt(p)
 	}
+	if p.jobs < 1 {
+		p.jobs = runtime.NumCPU()
+	}
+	return p
 }
 
 // ProcessManifest processes the manifest file, adjusts audio speed, and writes a new manifest for the synced files.
 func (p *Processor) ProcessManifest(manifestPath string) error {
-	entries, err := manifest.Parse(manifestPath)
+	return p.ProcessManifestContext(context.Background(), manifestPath)
+}
+
+// ProcessManifestContext is like ProcessManifest but stops when ctx is done.
+// The clips written so far, finished or not, are removed, and no synced
+// manifest is written.
+func (p *Processor) ProcessManifestContext(ctx context.Context, manifestPath string) error {
+	_, err := p.ProcessManifestReport(ctx, manifestPath)
+	return err
+}
+
+// ProcessManifestReport is like ProcessManifestContext but also reports what
+// was done with every entry. The report is returned even if the run fails.
+func (p *Processor) ProcessManifestReport(ctx context.Context, manifestPath string) (*RunReport, error) {
+	report := newRunReport("adjust-speed", manifestPath)
+	err := p.processManifest(ctx, report, manifestPath)
+	report.finish(ctx, err)
+	return report, err
+}
+
+func (p *Processor) processManifest(ctx context.Context, report *RunReport, manifestPath string) error {
+	m, err := manifest.Load(manifestPath, p.manifest)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrInvalidManifest, err)
 	}
+	s, err := p.manifestSettings(m)
+	if err != nil {
+		return err
+	}
+	entries := m.Entries
+	report.setEntries(entries)
 
-	var syncedEntries []manifest.ManifestEntry
-	for _, entry := range entries {
-		newEntry, err := p.processEntry(entry)
+	type result struct {
+		entry  manifest.ManifestEntry
+		change SpeedChange
+		err    error
+	}
+	results := make([]result, len(entries))
+	outputs := getOutputFilePaths(entries)
+
+	// Results are stored by index to keep the synced manifest in the original order.
+	p.parallel(ctx, len(entries), func(i int) {
+		started := time.Now()
+		// Each entry's progress is buffered and printed as one block, so
+		// concurrent entries don't interleave their output.
+		var progress bytes.Buffer
+		newEntry, change, outputDuration, err := p.processEntry(ctx, &progress, s, entries[i], outputs[i])
+		results[i] = result{entry: newEntry, change: change, err: err}
+		recordSpeedChange(ctx, &report.Entries[i], change, newEntry.FilePath, outputDuration, err)
+		report.Entries[i].Seconds = time.Since(started).Seconds()
+
+		p.outMu.Lock()
+		p.out.Write(progress.Bytes())
 		if err != nil {
 			// Log the error and continue to the next entry, so one failure doesn't stop the whole process.
-			log.Printf("Skipping entry for %s: %v", entry.FilePath, err)
+			log.Printf("Skipping entry for %s: %v", entries[i].FilePath, err)
+		}
+		p.outMu.Unlock()
+	})
+
+	if err := ctx.Err(); err != nil {
+		err = fmt.Errorf("processing interrupted: %w", err)
+		// No synced manifest will point to the clips that were finished,
+		// so don't leave them behind either.
+		for i, r := range results {
+			if r.err == nil && report.Entries[i].Status == StatusOK {
+				os.Remove(outputs[i])
+				report.Entries[i].fail(ctx, err)
+				report.Entries[i].Output = ""
+			}
+		}
+		return err
+	}
+
+	for i, e := range report.Entries {
+		if e.Status != StatusOK {
+			continue
+		}
+		if change := results[i].change; change.ClampedSpeed != change.Speed {
+			report.Totals.Clamped++
+		}
+		if e.ResidualMs > 0 {
+			report.Totals.OverrunMs += e.ResidualMs
 		} else {
-			syncedEntries = append(syncedEntries, newEntry)
+			report.Totals.UnderrunMs -= e.ResidualMs
+		}
+	}
+
+	var syncedEntries []manifest.ManifestEntry
+	for _, r := range results {
+		if r.err == nil {
+			syncedEntries = append(syncedEntries, r.entry)
 		}
 	}
 
 	// If any entries were successfully processed, write the new manifest.
 	if len(syncedEntries) > 0 {
 		syncedManifestPath := getSyncedManifestPath(manifestPath)
-		if err := manifest.Write(syncedManifestPath, syncedEntries); err != nil {
+		// Keep the input's format when it can be written, since some formats,
+		// e.g. Audacity labels, share their extension with others.
+		writeOpts := p.manifest
+		writeOpts.Format = ""
+		if m.Format.Writable() {
+			writeOpts.Format = m.Format
+		}
+		synced := *m
+		synced.Entries = syncedEntries
+		if s.bed != "" {
+			// The bed may have been resolved against --base-dir, which the
+			// synced manifest isn't read with.
+			synced.Directives = append([]manifest.Directive(nil), m.Directives...)
+			synced.SetDirective("bed", manifest.RelativePath(s.bed, filepath.Dir(syncedManifestPath)))
+		}
+		if err := manifest.WriteManifest(syncedManifestPath, &synced, writeOpts); err != nil {
 			return fmt.Errorf("failed to write synced manifest: %w", err)
 		}
-		fmt.Printf("\nSuccessfully created synced manifest: %s\n", syncedManifestPath)
+		report.Output = syncedManifestPath
+		fmt.Fprintf(p.out, "\nSuccessfully created synced manifest: %s\n", syncedManifestPath)
 	} else {
-		fmt.Println("\nNo audio files were successfully processed; synced manifest not created.")
+		fmt.Fprintln(p.out, "\nNo audio files were successfully processed; synced manifest not created.")
 	}
 
 	return nil
 }
 
-// processEntry handles the logic for a single manifest entry.
-// It returns a new ManifestEntry with the updated file path on success.
-func (p *Processor) processEntry(entry manifest.ManifestEntry) (manifest.ManifestEntry, error) {
-	fmt.Printf("Processing %s...\n", entry.FilePath)
-
-	manifestDuration := entry.EndTime - entry.StartTime
-	if manifestDuration <= 0 {
-		return manifest.ManifestEntry{}, fmt.Errorf("invalid duration in manifest (%.2fs)", manifestDuration)
+// parallel calls fn with every index below n, spread over a pool of p.jobs
+// workers, since entries are independent. No more indices are handed out once
+// ctx is done.
+func (p *Processor) parallel(ctx context.Context, n int, fn func(i int)) {
+	indices := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < p.jobs && w < n; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indices {
+				fn(i)
+			}
+		}()
+	}
+feed:
+	for i := 0; i < n; i++ {
+		select {
+		case indices <- i:
+		case <-ctx.Done():
+			break feed
+		}
 	}
+	close(indices)
+	wg.Wait()
+}
+
+// processEntry handles the logic for a single manifest entry, writing the
+// adjusted clip to outputFilePath and its progress to w.
+// It returns a new ManifestEntry with the updated file path on success, the
+// speed change worked out for the entry, and the duration of the adjusted
+// clip as probed after writing it.
+func (p *Processor) processEntry(ctx context.Context, w io.Writer, s settings, entry manifest.ManifestEntry, outputFilePath string) (manifest.ManifestEntry, SpeedChange, float64, error) {
+	fmt.Fprintf(w, "Processing %s...\n", entry.FilePath)
 
-	actualDuration, err := p.audioProc.GetDuration(entry.FilePath)
+	change, err := p.planEntry(ctx, s, entry)
 	if err != nil {
-		return manifest.ManifestEntry{}, fmt.Errorf("%w: %w", ErrProcessingEntry, err)
+		return manifest.ManifestEntry{}, change, 0, err
 	}
-
-	if actualDuration == 0 {
-		return manifest.ManifestEntry{}, fmt.Errorf("actual duration is zero")
+	fmt.Fprintf(w, "  Manifest duration: %.2fs\n", change.ManifestDuration)
+	fmt.Fprintf(w, "  Actual duration:   %.2fs\n", change.ActualDuration)
+	fmt.Fprintf(w, "  Original speed factor: %.2f\n", change.Speed)
+	if change.ClampedSpeed != change.Speed {
+		fmt.Fprintf(w, "  Clamped speed factor:  %.2f\n", change.ClampedSpeed)
 	}
 
-	speedFactor := actualDuration / manifestDuration
-	fmt.Printf("  Manifest duration: %.2fs\n", manifestDuration)
-	fmt.Printf("  Actual duration:   %.2fs\n", actualDuration)
-	fmt.Printf("  Original speed factor: %.2f\n", speedFactor)
-
-	// Clamp the speed factor to the allowed range.
-	clampedSpeed := clamp(speedFactor, minSpeed, maxSpeed)
-	if clampedSpeed != speedFactor {
-		fmt.Printf("  Clamped speed factor:  %.2f\n", clampedSpeed)
+	if err := p.applySpeed(ctx, entry.FilePath, outputFilePath, change.ClampedSpeed); err != nil {
+		// Don't leave a half-written clip behind, whatever the backend did.
+		os.Remove(outputFilePath)
+		return manifest.ManifestEntry{}, change, 0, fmt.Errorf("%w: %w", ErrProcessingEntry, err)
 	}
 
-	outputFilePath := getOutputFilePath(entry.FilePath)
-	if err := p.audioProc.ApplySpeed(entry.FilePath, outputFilePath, clampedSpeed); err != nil {
-		return manifest.ManifestEntry{}, fmt.Errorf("%w: %w", ErrProcessingEntry, err)
+	// Backends may round the factor or pad the clip, so measure the result
+	// rather than trusting the plan.
+	outputDuration, err := p.getDuration(ctx, outputFilePath)
+	if err != nil {
+		os.Remove(outputFilePath)
+		return manifest.ManifestEntry{}, change, 0, fmt.Errorf("%w: failed to read duration of %s: %w", ErrProcessingEntry, outputFilePath, err)
 	}
+	if outputDuration <= 0 {
+		os.Remove(outputFilePath)
+		return manifest.ManifestEntry{}, change, 0, fmt.Errorf("%w: %s has no duration", ErrProcessingEntry, outputFilePath)
+	}
+	fmt.Fprintf(w, "  Successfully created %s (%.2fs)\n", outputFilePath, outputDuration)
 
-	fmt.Printf("  Successfully created %s\n", outputFilePath)
-
-	// Return a new entry pointing to the synced file.
-	return manifest.ManifestEntry{
-		StartTime: entry.StartTime,
-		EndTime:   entry.EndTime,
-		Speaker:   entry.Speaker,
-		FilePath:  outputFilePath,
-	}, nil
+	// Return a copy of the entry pointing to the synced file.
+	synced := entry
+	synced.FilePath = outputFilePath
+	return synced, change, outputDuration, nil
 }
 
-// BuildFromManifest creates a single audio file by concatenating clips from a manifest.
+// BuildFromManifest creates a single audio file from the clips in a manifest.
+// The whole timeline is planned first and then rendered in a single pass.
 func (p *Processor) BuildFromManifest(manifestPath, outputPath string) error {
-	entries, err := manifest.Parse(manifestPath)
+	return p.BuildFromManifestContext(context.Background(), manifestPath, outputPath)
+}
+
+// BuildFromManifestContext is like BuildFromManifest but stops when ctx is
+// done, removing the partially rendered output.
+func (p *Processor) BuildFromManifestContext(ctx context.Context, manifestPath, outputPath string) error {
+	_, err := p.BuildFromManifestReport(ctx, manifestPath, outputPath)
+	return err
+}
+
+// BuildFromManifestReport is like BuildFromManifestContext but also reports
+// where every clip was placed. The report is returned even if the build
+// fails.
+func (p *Processor) BuildFromManifestReport(ctx context.Context, manifestPath, outputPath string) (*RunReport, error) {
+	report := newRunReport("build", manifestPath)
+	err := p.buildFromManifest(ctx, report, manifestPath, outputPath)
+	report.finish(ctx, err)
+	return report, err
+}
+
+func (p *Processor) buildFromManifest(ctx context.Context, report *RunReport, manifestPath, outputPath string) error {
+	m, err := manifest.Load(manifestPath, p.manifest)
 	if err != nil {
 		return fmt.Errorf("%w: %w", ErrInvalidManifest, err)
 	}
+	s, err := p.manifestSettings(m)
+	if err != nil {
+		return err
+	}
+	entries := m.Entries
+	report.setEntries(entries)
 
 	if len(entries) == 0 {
 		return fmt.Errorf("cannot build from an empty manifest")
 	}
 
-	// Create a temporary directory for intermediate files.
-	tempDir, err := os.MkdirTemp("", "sync-audio-build-")
+	renderer, ok := p.audioProc.(audio.Renderer)
+	if !ok {
+		return fmt.Errorf("audio backend %T does not support timeline rendering", p.audioProc)
+	}
+
+	plan, err := p.planTimeline(ctx, renderer, s, entries)
+	timeline := plan.timeline
+	rate := float64(timeline.Format.SampleRate)
+	// Placed entries stay skipped until the timeline is rendered.
+	for _, pl := range plan.placements {
+		e := &report.Entries[pl.index]
+		e.ActualDuration = pl.duration
+		e.ResidualMs = pl.errorSeconds(rate) * 1000
+		realized := float64(pl.offset) / rate
+		e.RealizedStart = &realized
+		if e.ResidualMs > report.Totals.WorstLateMs {
+			report.Totals.WorstLateMs = e.ResidualMs
+		}
+		if pl.overflow != "" {
+			e.Overflow = pl.overflow
+			e.OverflowMs = float64(pl.overflowFrames) / rate * 1000
+			report.Totals.Overflows++
+		}
+	}
 	if err != nil {
-		return fmt.Errorf("failed to create temp directory: %w", err)
+		// Planning stopped at the entry after the last one placed.
+		if i := len(plan.placements); i < len(entries) {
+			report.Entries[i].fail(ctx, err)
+		}
+		return err
 	}
-	defer os.RemoveAll(tempDir)
+	report.Totals.OutputDuration = float64(timeline.Frames) / rate
 
-	var currentTime float64
-	var currentBuildFile string
+	if worst, ok := plan.worstPlacement(); ok {
+		fmt.Fprintf(p.out, "\nWorst placement error: %.3fms (entry %d, %s)\n", worst.errorSeconds(rate)*1000, worst.index+1, worst.entry.FilePath)
+	} else {
+		fmt.Fprintf(p.out, "\nAll clips placed exactly at their start times.\n")
+	}
+	if n := report.Totals.Overflows; n > 0 {
+		fmt.Fprintf(p.out, "Overflow policy %s applied to %d clips.\n", p.overflowPolicy(), n)
+	}
 
-	for i, entry := range entries {
-		fmt.Printf("Step %d/%d: Processing %s\n", i+1, len(entries), entry.FilePath)
+	fmt.Fprintf(p.out, "Rendering %d clips (%.2fs) to %s\n", len(timeline.Clips), float64(timeline.Frames)/rate, outputPath)
+	renderStarted := time.Now()
+	err = p.render(ctx, renderer, timeline, outputPath)
+	report.RenderSeconds = time.Since(renderStarted).Seconds()
+	if err != nil {
+		os.Remove(outputPath)
+		err = fmt.Errorf("failed to render timeline: %w", err)
+		for _, pl := range plan.placements {
+			report.Entries[pl.index].fail(ctx, err)
+		}
+		return err
+	}
+	for _, pl := range plan.placements {
+		report.Entries[pl.index].Status = StatusOK
+	}
+	report.Output = outputPath
 
-		// 1. Calculate and generate silence if there's a gap.
-		gap := entry.StartTime - currentTime
-		filesToConcat := []string{}
+	return nil
+}
 
-		if i > 0 {
-			filesToConcat = append(filesToConcat, currentBuildFile)
-		}
+// placement records where a manifest entry lands on the rendered timeline.
+// All positions are in sample frames at the output sample rate.
+type placement struct {
+	index  int
+	entry  manifest.ManifestEntry
+	target int64
+	offset int64
+	frames int64
+	// duration is the length of the clip in seconds, as probed.
+	duration float64
+	// overflow says what the overflow policy did to the clip: "pushed",
+	// "overlapped" or "truncated", by overflowFrames.
+	overflow       string
+	overflowFrames int64
+}
 
-		if gap > epsilon {
-			fmt.Printf("  Adding %.2fs of silence.\n", gap)
-			silenceFile := filepath.Join(tempDir, fmt.Sprintf("silence_%d.wav", i))
-			if err := p.audioProc.GenerateSilence(gap, silenceFile); err != nil {
-				return fmt.Errorf("failed to generate silence for entry %d: %w", i, err)
-			}
-			filesToConcat = append(filesToConcat, silenceFile)
-		}
+// errorSeconds returns how late the clip starts relative to its manifest start time.
+func (pl placement) errorSeconds(rate float64) float64 {
+	return float64(pl.offset-pl.target) / rate
+}
 
-		// 2. Add the actual audio clip to the list.
-		filesToConcat = append(filesToConcat, entry.FilePath)
+// timelinePlan is the result of laying out a manifest before rendering.
+type timelinePlan struct {
+	timeline   audio.Timeline
+	placements []placement
+}
 
-		// 3. Concatenate the files.
-		nextBuildFile := filepath.Join(tempDir, fmt.Sprintf("build_step_%d.wav", i))
-		fmt.Printf("  Concatenating %d files...\n", len(filesToConcat))
-		if err := p.audioProc.Concatenate(filesToConcat, nextBuildFile); err != nil {
-			return fmt.Errorf("failed to concatenate files for entry %d: %w", i, err)
+// worstPlacement returns the placement furthest from its target, if any clip was displaced.
+func (plan timelinePlan) worstPlacement() (placement, bool) {
+	var worst placement
+	var worstError int64
+	for _, pl := range plan.placements {
+		if e := abs(pl.offset - pl.target); e > worstError {
+			worst, worstError = pl, e
 		}
+	}
+	return worst, worstError > 0
+}
+
+// planTimeline places every clip at round(StartTime*rate) frames, or on its
+// exact video frame position (see targetOffset). A clip whose slot is still
+// occupied by the previous clip is handled by the overflow policy. The output
+// takes the sample format of the first clip, unless the manifest sets a
+// sample rate. A bed from the manifest is mixed in from the start and cut at
+// the end of the last clip. If planning fails, the plan holds the clips
+// placed before the failure.
+func (p *Processor) planTimeline(ctx context.Context, renderer audio.Renderer, s settings, entries []manifest.ManifestEntry) (timelinePlan, error) {
+	format, err := p.outputFormat(ctx, renderer, s, entries)
+	if err != nil {
+		return timelinePlan{}, err
+	}
+	rate := float64(format.SampleRate)
+
+	plan := timelinePlan{timeline: audio.Timeline{Format: format}}
+	if s.bed != "" {
+		fmt.Fprintf(p.out, "Mixing bed %s\n", s.bed)
+		plan.timeline.Clips = append(plan.timeline.Clips, audio.Clip{File: s.bed, Gain: s.bedGain})
+	}
+	policy := p.overflowPolicy()
+	// cursor is where the latest-ending clip so far ends, and last is its
+	// entry.
+	var cursor int64
+	last := -1
+	for i, entry := range entries {
+		fmt.Fprintf(p.out, "Step %d/%d: Processing %s\n", i+1, len(entries), entry.FilePath)
 
-		// The output of this step becomes the input for the next.
-		currentBuildFile = nextBuildFile
-		// Update the timeline.
-		newDuration, err := p.audioProc.GetDuration(currentBuildFile)
+		duration, err := p.getDuration(ctx, entry.FilePath)
 		if err != nil {
-			return fmt.Errorf("failed to get duration of intermediate build file: %w", err)
+			return plan, fmt.Errorf("failed to get duration for entry %d: %w", i, err)
+		}
+
+		pl := placement{
+			index:    i,
+			entry:    entry,
+			target:   p.targetOffset(entry.StartTime, format.SampleRate),
+			frames:   int64(math.Round(duration * rate)),
+			duration: duration,
 		}
-		currentTime = newDuration
-		fmt.Printf("  Intermediate track duration: %.2fs\n", currentTime)
+		pl.offset = pl.target
+		if policy == OverflowBorrowGap && i > 0 {
+			// The silence between the previous slot and this one takes as
+			// much of the overrun as it can; the rest makes this clip late.
+			slotEnd := p.targetOffset(entries[i-1].EndTime, format.SampleRate)
+			if gap := pl.target - slotEnd; gap > 0 && cursor > slotEnd {
+				borrowed := cursor - slotEnd
+				if borrowed > gap {
+					borrowed = gap
+				}
+				fmt.Fprintf(p.out, "  Previous clip overruns; borrowing %.3fs of the %.3fs gap.\n", float64(borrowed)/rate, float64(gap)/rate)
+			}
+		}
+		if over := cursor - pl.offset; over > 0 {
+			seconds := float64(over) / rate
+			switch {
+			case policy == OverflowFail:
+				return plan, fmt.Errorf("%w: entry %d starts %.3fs before entry %d ends", ErrOverflow, i+1, seconds, last+1)
+			case policy == OverflowOverlap:
+				pl.overflow, pl.overflowFrames = "overlapped", over
+				fmt.Fprintf(p.out, "  Previous clip overruns; overlapping it by %.3fs.\n", seconds)
+			case policy == OverflowTruncate && pl.offset > plan.placements[last].offset:
+				prev := &plan.placements[last]
+				cut := pl.offset - prev.offset
+				prev.overflow, prev.overflowFrames = "truncated", prev.frames-cut
+				prev.frames = cut
+				// One clip follows the bed, if any, for every entry so far.
+				clip := &plan.timeline.Clips[len(plan.timeline.Clips)-i+last]
+				clip.Frames = cut
+				clip.FadeOut = int64(math.Round(truncateFadeOut * rate))
+				if clip.FadeOut > cut {
+					clip.FadeOut = cut
+				}
+				cursor = pl.offset
+				fmt.Fprintf(p.out, "  Previous clip overruns; cutting it %.3fs short.\n", seconds)
+			default:
+				// Push and borrow-gap, and truncate when the previous clip
+				// doesn't start before this one.
+				pl.offset = cursor
+			}
+		} else if pl.offset > cursor {
+			fmt.Fprintf(p.out, "  Adding %.3fs of silence.\n", float64(pl.offset-cursor)/rate)
+		}
+		if late := pl.offset - pl.target; late > 0 {
+			pl.overflow, pl.overflowFrames = "pushed", late
+			fmt.Fprintf(p.out, "  Previous clip overruns; starting %.3fs late.\n", float64(late)/rate)
+		}
+		if end := pl.offset + pl.frames; end > cursor {
+			cursor, last = end, i
+		}
+
+		plan.placements = append(plan.placements, pl)
+		plan.timeline.Clips = append(plan.timeline.Clips, audio.Clip{File: entry.FilePath, Offset: pl.offset, Gain: entry.Gain})
 	}
+	plan.timeline.Frames = cursor
+
+	return plan, nil
+}
 
-	// Finally, move the last intermediate file to the final output path.
-	fmt.Printf("\nBuild complete. Moving final file to %s\n", outputPath)
-	if err := os.Rename(currentBuildFile, outputPath); err != nil {
-		return fmt.Errorf("failed to move final build file: %w", err)
+// outputFormat returns the sample format of the track built from entries:
+// that of the first clip, at the sample rate the manifest sets, if any.
+func (p *Processor) outputFormat(ctx context.Context, renderer audio.Renderer, s settings, entries []manifest.ManifestEntry) (audio.Format, error) {
+	format, err := p.getFormat(ctx, renderer, entries[0].FilePath)
+	if err != nil {
+		return audio.Format{}, fmt.Errorf("failed to read format of %s: %w", entries[0].FilePath, err)
+	}
+	if s.sampleRate > 0 && s.sampleRate != format.SampleRate {
+		if !resamples(renderer) {
+			return audio.Format{}, fmt.Errorf("%w: sample_rate=%d differs from the %d Hz of %s", ErrCannotResample, s.sampleRate, format.SampleRate, entries[0].FilePath)
+		}
+		format.SampleRate = s.sampleRate
 	}
+	return format, nil
+}
 
-	return nil
+// resamples reports whether renderer can mix clips at other sample rates than
+// the timeline's.
+func resamples(renderer audio.Renderer) bool {
+	r, ok := renderer.(audio.Resampler)
+	return ok && r.Resamples()
+}
+
+// targetOffset returns the sample frame at which a clip starting at start
+// seconds belongs. With a video frame rate set, starts on a frame boundary
+// are converted from the frame number exactly rather than from rounded seconds.
+func (p *Processor) targetOffset(start float64, sampleRate int) int64 {
+	if fps := p.manifest.FrameRate; !fps.IsZero() {
+		if frame, ok := fps.FrameAt(start); ok {
+			return fps.SampleOffset(frame, sampleRate)
+		}
+	}
+	return int64(math.Round(start * float64(sampleRate)))
 }
 
 // getSyncedManifestPath generates the name for the new manifest file.
+// Manifests imported from formats that can't be written get a .txt extension.
 func getSyncedManifestPath(inputPath string) string {
 	dir := filepath.Dir(inputPath)
 	base := filepath.Base(inputPath)
 	ext := filepath.Ext(base)
 	name := strings.TrimSuffix(base, ext)
+	if format, ok := manifest.FormatFromExtension(inputPath); ok && !format.Writable() {
+		ext = ".txt"
+	}
 	return filepath.Join(dir, fmt.Sprintf("%s_synced%s", name, ext))
 }
 
 func getOutputFilePath(inputPath string) string {
+	return numberedOutputFilePath(inputPath, 1)
+}
+
+// getOutputFilePaths returns the output path of every entry. Entries that
+// reuse a clip get numbered outputs, e.g. a_synced_2.wav, so that entries
+// processed in parallel never write the same file.
+func getOutputFilePaths(entries []manifest.ManifestEntry) []string {
+	paths := make([]string, len(entries))
+	used := make(map[string]bool, len(entries))
+	for i, entry := range entries {
+		path := getOutputFilePath(entry.FilePath)
+		for n := 2; used[path]; n++ {
+			path = numberedOutputFilePath(entry.FilePath, n)
+		}
+		used[path] = true
+		paths[i] = path
+	}
+	return paths
+}
+
+// numberedOutputFilePath returns the nth output path for inputPath; only
+// the ones after the first carry their number.
+func numberedOutputFilePath(inputPath string, n int) string {
 	dir := filepath.Dir(inputPath)
 	base := filepath.Base(inputPath)
 	ext := filepath.Ext(base)
-	name := strings.TrimSuffix(base, ext)
-	return filepath.Join(dir, fmt.Sprintf("%s_synced%s", name, ext))
+	name := strings.TrimSuffix(base, ext) + "_synced"
+	if n > 1 {
+		name = fmt.Sprintf("%s_%d", name, n)
+	}
+	return filepath.Join(dir, name+ext)
 }
 
 func clamp(value, min, max float64) float64 {
@@ -205,3 +628,10 @@ func clamp(value, min, max float64) float64 {
 	}
 	return value
 }
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
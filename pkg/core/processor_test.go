@@ -1,17 +1,34 @@
 package core
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"io"
+	"math"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
 )
 
 // MockAudioProcessor is a mock implementation of the audio.Processor for testing.
 type MockAudioProcessor struct {
-	GetDurationFunc func(filePath string) (float64, error)
-	ApplySpeedFunc  func(inputFile, outputFile string, speed float64) error
+	GetDurationFunc     func(filePath string) (float64, error)
+	ApplySpeedFunc      func(inputFile, outputFile string, speed float64) error
+	GenerateSilenceFunc func(duration float64, outputFile string) error
+	ConcatenateFunc     func(inputFiles []string, outputFile string) error
+	GetFormatFunc       func(filePath string) (audio.Format, error)
+	RenderFunc          func(tl audio.Timeline, outputFile string) error
+	// CannotResample makes the mock render like a backend that needs every
+	// clip at the timeline's sample rate.
+	CannotResample bool
 }
 
 func (m *MockAudioProcessor) GetDuration(filePath string) (float64, error) {
@@ -28,32 +45,64 @@ func (m *MockAudioProcessor) ApplySpeed(inputFile, outputFile string, speed floa
 	return fmt.Errorf("ApplySpeedFunc not implemented")
 }
 
+func (m *MockAudioProcessor) GenerateSilence(duration float64, outputFile string) error {
+	if m.GenerateSilenceFunc != nil {
+		return m.GenerateSilenceFunc(duration, outputFile)
+	}
+	return fmt.Errorf("GenerateSilenceFunc not implemented")
+}
+
+func (m *MockAudioProcessor) Concatenate(inputFiles []string, outputFile string) error {
+	if m.ConcatenateFunc != nil {
+		return m.ConcatenateFunc(inputFiles, outputFile)
+	}
+	return fmt.Errorf("ConcatenateFunc not implemented")
+}
+
+func (m *MockAudioProcessor) GetFormat(filePath string) (audio.Format, error) {
+	if m.GetFormatFunc != nil {
+		return m.GetFormatFunc(filePath)
+	}
+	return audio.Format{SampleRate: 1000, Channels: 1, BitsPerSample: 16}, nil
+}
+
+func (m *MockAudioProcessor) Resamples() bool {
+	return !m.CannotResample
+}
+
+func (m *MockAudioProcessor) Render(tl audio.Timeline, outputFile string) error {
+	if m.RenderFunc != nil {
+		return m.RenderFunc(tl, outputFile)
+	}
+	return fmt.Errorf("RenderFunc not implemented")
+}
+
 func TestProcessor_ProcessManifest_Clamping(t *testing.T) {
 	testCases := []struct {
-		name               string
-		manifestDuration   float64
-		actualDuration     float64
-		expectedSpeed      float64
-		shouldApplySpeed   bool
+		name             string
+		manifestDuration float64
+		actualDuration   float64
+		expectedSpeed    float64
+		shouldApplySpeed bool
 	}{
 		{
 			name:             "Speed up clamped to max",
-			manifestDuration: 20.0,
-			actualDuration:   10.0,
+			manifestDuration: 10.0,
+			actualDuration:   20.0,
 			expectedSpeed:    maxSpeed,
 			shouldApplySpeed: true,
 		},
 		{
 			name:             "Slow down clamped to min",
-			manifestDuration: 5.0,
-			actualDuration:   10.0,
+			manifestDuration: 10.0,
+			actualDuration:   5.0,
 			expectedSpeed:    minSpeed,
 			shouldApplySpeed: true,
 		},
 		{
 			name:             "Speed within range",
-			manifestDuration: 12.0,
-			actualDuration:   10.0,
+			manifestDuration: 10.0,
+			actualDuration:   12.0,
 			expectedSpeed:    1.2,
 			shouldApplySpeed: true,
 		},
@@ -130,3 +179,621 @@ func TestProcessor_ProcessManifest_ErrorHandling(t *testing.T) {
 		}
 	})
 }
+
+func TestProcessor_BuildFromManifest_Timeline(t *testing.T) {
+	durations := map[string]float64{
+		"/fake/a.wav": 2.0,
+		"/fake/b.wav": 3.5,
+		"/fake/c.wav": 1.0,
+	}
+
+	var rendered audio.Timeline
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return durations[filePath], nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			rendered = tl
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	// b.wav overruns its slot, so c.wav follows it directly instead of starting at 5.5s.
+	manifestContent := `[0.5s–2.5s] (SPEAKER_00) /fake/a.wav
+[3.0s–5.5s] (SPEAKER_01) /fake/b.wav
+[5.5s–6.5s] (SPEAKER_00) /fake/c.wav
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav")); err != nil {
+		t.Fatalf("BuildFromManifest() error = %v", err)
+	}
+
+	expectedOffsets := []int64{500, 3000, 6500}
+	if len(rendered.Clips) != len(expectedOffsets) {
+		t.Fatalf("expected %d clips, got %d", len(expectedOffsets), len(rendered.Clips))
+	}
+	for i, offset := range expectedOffsets {
+		if rendered.Clips[i].Offset != offset {
+			t.Errorf("clip %d: expected offset %d, got %d", i, offset, rendered.Clips[i].Offset)
+		}
+	}
+	if rendered.Frames != 7500 {
+		t.Errorf("expected 7500 frames, got %d", rendered.Frames)
+	}
+}
+
+func TestProcessor_BuildFromManifest_JSONGain(t *testing.T) {
+	var rendered audio.Timeline
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			rendered = tl
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	manifestContent := `{"version": 1, "entries": [
+  {"start": 0, "end": 1, "path": "/fake/a.wav", "gain": -6},
+  {"start": 1, "end": 2, "path": "/fake/b.wav"}
+]}`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.json")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav")); err != nil {
+		t.Fatalf("BuildFromManifest() error = %v", err)
+	}
+
+	if len(rendered.Clips) != 2 || rendered.Clips[0].Gain != -6 || rendered.Clips[1].Gain != 0 {
+		t.Errorf("expected clip gains [-6 0], got %+v", rendered.Clips)
+	}
+}
+
+func TestProcessor_BuildFromManifest_Directives(t *testing.T) {
+	var rendered audio.Timeline
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			rendered = tl
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	manifestContent := `# Episode 1
+#! sample_rate=2000
+#! bed=/fake/music.wav
+#! bed_gain=-18
+
+[0.5s–1.5s] (SPEAKER_00) /fake/a.wav
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav")); err != nil {
+		t.Fatalf("BuildFromManifest() error = %v", err)
+	}
+
+	if rendered.Format.SampleRate != 2000 {
+		t.Errorf("expected a 2000 Hz timeline, got %d Hz", rendered.Format.SampleRate)
+	}
+	expected := []audio.Clip{
+		{File: "/fake/music.wav", Gain: -18},
+		{File: "/fake/a.wav", Offset: 1000},
+	}
+	if !reflect.DeepEqual(rendered.Clips, expected) {
+		t.Errorf("expected clips %+v, got %+v", expected, rendered.Clips)
+	}
+	if rendered.Frames != 3000 {
+		t.Errorf("expected the bed to be cut at 3000 frames, got %d", rendered.Frames)
+	}
+}
+
+func TestProcessor_BuildFromManifest_SampleRateWithoutResampling(t *testing.T) {
+	testCases := []struct {
+		name       string
+		sampleRate int
+		wantErr    bool
+	}{
+		{"other rate", 2000, true},
+		{"clip rate", 1000, false},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			rendered := false
+			mockAudioProc := &MockAudioProcessor{
+				GetDurationFunc: func(filePath string) (float64, error) {
+					return 1.0, nil
+				},
+				RenderFunc: func(tl audio.Timeline, outputFile string) error {
+					rendered = true
+					return nil
+				},
+				CannotResample: true,
+			}
+			processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+			tmpDir := t.TempDir()
+			manifestPath := filepath.Join(tmpDir, "manifest.txt")
+			content := fmt.Sprintf("#! sample_rate=%d\n[0.0s–1.0s] (A) /fake/a.wav\n", tc.sampleRate)
+			if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav"))
+			if tc.wantErr {
+				if !errors.Is(err, ErrCannotResample) || rendered {
+					t.Errorf("expected ErrCannotResample before rendering, got %v (rendered: %v)", err, rendered)
+				}
+			} else if err != nil {
+				t.Errorf("BuildFromManifest() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessManifest_Directives(t *testing.T) {
+	var appliedSpeed float64
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 5.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			appliedSpeed = speed
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	manifestContent := `#! min_speed=0.8
+
+# Act 1
+[0.0s–6.0s] (SPEAKER_00) fake/a.wav
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.ProcessManifest(manifestPath); err != nil {
+		t.Fatalf("ProcessManifest() error = %v", err)
+	}
+	if math.Abs(appliedSpeed-5.0/6.0) > 1e-9 {
+		t.Errorf("expected speed %.4f within min_speed, got %.4f", 5.0/6.0, appliedSpeed)
+	}
+
+	synced, err := os.ReadFile(filepath.Join(tmpDir, "manifest_synced.txt"))
+	if err != nil {
+		t.Fatalf("failed to read synced manifest: %v", err)
+	}
+	expected := "#! min_speed=0.8\n\n# Act 1\n[0.0s–6.0s] (SPEAKER_00) fake/a_synced.wav\n"
+	if string(synced) != expected {
+		t.Errorf("expected synced manifest %q, got %q", expected, synced)
+	}
+}
+
+func TestProcessor_BuildFromManifest_SampleAccurate(t *testing.T) {
+	var rendered audio.Timeline
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		GetFormatFunc: func(filePath string) (audio.Format, error) {
+			return audio.Format{SampleRate: 48000, Channels: 2, BitsPerSample: 24}, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			rendered = tl
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	// The 5ms gap between the clips must be kept, and the fractional start
+	// time must round to the nearest sample rather than accumulate drift.
+	manifestContent := `[0.0s–1.0s] (SPEAKER_00) /fake/a.wav
+[1.005s–2.005s] (SPEAKER_01) /fake/b.wav
+[3600.00001s–3601.0s] (SPEAKER_00) /fake/c.wav
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav")); err != nil {
+		t.Fatalf("BuildFromManifest() error = %v", err)
+	}
+
+	expectedOffsets := []int64{0, 48240, 172800000}
+	for i, offset := range expectedOffsets {
+		if rendered.Clips[i].Offset != offset {
+			t.Errorf("clip %d: expected offset %d, got %d", i, offset, rendered.Clips[i].Offset)
+		}
+	}
+}
+
+func TestProcessor_BuildFromManifest_FrameAligned(t *testing.T) {
+	var rendered audio.Timeline
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 0.5, nil
+		},
+		GetFormatFunc: func(filePath string) (audio.Format, error) {
+			return audio.Format{SampleRate: 44100, Channels: 1, BitsPerSample: 16}, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			rendered = tl
+			return nil
+		},
+	}
+	fps, err := manifest.ParseFrameRate("23.976")
+	if err != nil {
+		t.Fatalf("ParseFrameRate() error = %v", err)
+	}
+	processor := NewProcessor(mockAudioProc, WithManifestOptions(manifest.Options{FrameRate: fps}))
+
+	// Frame 40 at 24000/1001 fps starts exactly at sample 73573.5, which
+	// rounds up; frame 1000 starts at sample 1839337.5.
+	manifestContent := `[00:00:01:16–00:00:02:00] (SPEAKER_00) /fake/a.wav
+[00:00:41:16–00:00:42:00] (SPEAKER_00) /fake/b.wav
+`
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.BuildFromManifest(manifestPath, filepath.Join(tmpDir, "out.wav")); err != nil {
+		t.Fatalf("BuildFromManifest() error = %v", err)
+	}
+
+	expectedOffsets := []int64{73574, 1839338}
+	for i, offset := range expectedOffsets {
+		if rendered.Clips[i].Offset != offset {
+			t.Errorf("clip %d: expected offset %d, got %d", i, offset, rendered.Clips[i].Offset)
+		}
+	}
+}
+
+func TestProcessor_ProcessManifest_ParallelKeepsOrder(t *testing.T) {
+	const entries = 8
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			// Make earlier entries finish last.
+			var index int
+			fmt.Sscanf(filepath.Base(inputFile), "%d.wav", &index)
+			time.Sleep(time.Duration(entries-index) * time.Millisecond)
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithJobs(4))
+
+	var manifestContent strings.Builder
+	for i := 0; i < entries; i++ {
+		fmt.Fprintf(&manifestContent, "[%d.0s–%d.0s] (SPEAKER_00) /fake/%d.wav\n", i, i+1, i)
+	}
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent.String()), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.ProcessManifest(manifestPath); err != nil {
+		t.Fatalf("ProcessManifest() error = %v", err)
+	}
+
+	synced, err := manifest.Parse(filepath.Join(tmpDir, "manifest_synced.txt"))
+	if err != nil {
+		t.Fatalf("failed to parse synced manifest: %v", err)
+	}
+	if len(synced) != entries {
+		t.Fatalf("expected %d entries, got %d", entries, len(synced))
+	}
+	for i, entry := range synced {
+		if expected := fmt.Sprintf("/fake/%d_synced.wav", i); entry.FilePath != expected {
+			t.Errorf("entry %d: expected %s, got %s", i, expected, entry.FilePath)
+		}
+	}
+}
+
+func TestProcessor_ProcessManifest_RepeatedClip(t *testing.T) {
+	var mu sync.Mutex
+	speeds := make(map[string]float64)
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			mu.Lock()
+			defer mu.Unlock()
+			if _, ok := speeds[outputFile]; ok {
+				t.Errorf("%s written twice", outputFile)
+			}
+			speeds[outputFile] = speed
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithJobs(4))
+
+	manifestContent := "[0.0s–1.0s] (A) /fake/a.wav\n[1.0s–1.8s] (A) /fake/a.wav\n[2.0s–3.0s] (A) /fake/b.wav\n[3.0s–4.1s] (A) /fake/a.wav\n"
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.ProcessManifest(manifestPath); err != nil {
+		t.Fatalf("ProcessManifest() error = %v", err)
+	}
+
+	synced, err := manifest.Parse(filepath.Join(tmpDir, "manifest_synced.txt"))
+	if err != nil {
+		t.Fatalf("failed to parse synced manifest: %v", err)
+	}
+	expected := []string{"/fake/a_synced.wav", "/fake/a_synced_2.wav", "/fake/b_synced.wav", "/fake/a_synced_3.wav"}
+	if len(synced) != len(expected) {
+		t.Fatalf("expected %d entries, got %d", len(expected), len(synced))
+	}
+	for i, entry := range synced {
+		if entry.FilePath != expected[i] {
+			t.Errorf("entry %d: expected %s, got %s", i, expected[i], entry.FilePath)
+		}
+		// Each output keeps the speed of its own entry.
+		want := clamp(1.0/(entry.EndTime-entry.StartTime), minSpeed, maxSpeed)
+		if math.Abs(speeds[entry.FilePath]-want) > 1e-9 {
+			t.Errorf("entry %d: expected speed %.3f, got %.3f", i, want, speeds[entry.FilePath])
+		}
+	}
+}
+
+func TestProcessor_ProcessManifest_KeepsInputFormat(t *testing.T) {
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc)
+
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "labels.txt")
+	if err := os.WriteFile(manifestPath, []byte("0.000000\t1.000000\tSPEAKER_00|fake/a.wav\n"), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	if err := processor.ProcessManifest(manifestPath); err != nil {
+		t.Fatalf("ProcessManifest() error = %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "labels_synced.txt"))
+	if err != nil {
+		t.Fatalf("failed to read synced manifest: %v", err)
+	}
+	if expected := "0.000000\t1.000000\tSPEAKER_00|fake/a_synced.wav\n"; string(data) != expected {
+		t.Errorf("expected an Audacity label track %q, got %q", expected, data)
+	}
+}
+
+func TestProcessor_ProcessManifest_RelativePaths(t *testing.T) {
+	// The manifest and the media are in sibling directories of a project
+	// folder.
+	projectDir := t.TempDir()
+	tmpDir := filepath.Join(projectDir, "manifests")
+	mediaDir := filepath.Join(projectDir, "media")
+	if err := os.Mkdir(tmpDir, 0755); err != nil {
+		t.Fatalf("failed to create manifest dir: %v", err)
+	}
+
+	testCases := []struct {
+		name         string
+		opts         manifest.Options
+		expectedClip string
+		expectedOut  string
+	}{
+		{
+			name:         "manifest dir",
+			expectedClip: filepath.Join(tmpDir, "clips", "a.wav"),
+			expectedOut:  "#! bed=music.wav\n[0.0s–1.0s] (A) clips/a_synced.wav\n",
+		},
+		{
+			name:         "base dir",
+			opts:         manifest.Options{BaseDir: mediaDir},
+			expectedClip: filepath.Join(mediaDir, "clips", "a.wav"),
+			expectedOut:  "#! bed=../media/music.wav\n[0.0s–1.0s] (A) ../media/clips/a_synced.wav\n",
+		},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			var probed string
+			mockAudioProc := &MockAudioProcessor{
+				GetDurationFunc: func(filePath string) (float64, error) {
+					// The clip is probed before its output.
+					if probed == "" {
+						probed = filePath
+					}
+					return 1.0, nil
+				},
+				ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+					return nil
+				},
+			}
+			processor := NewProcessor(mockAudioProc, WithManifestOptions(tc.opts))
+
+			manifestPath := filepath.Join(tmpDir, "manifest.txt")
+			if err := os.WriteFile(manifestPath, []byte("#! bed=music.wav\n[0.0s–1.0s] (A) clips/a.wav\n"), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+
+			if err := processor.ProcessManifest(manifestPath); err != nil {
+				t.Fatalf("ProcessManifest() error = %v", err)
+			}
+			if probed != tc.expectedClip {
+				t.Errorf("expected clip %s, got %s", tc.expectedClip, probed)
+			}
+			synced, err := os.ReadFile(filepath.Join(tmpDir, "manifest_synced.txt"))
+			if err != nil {
+				t.Fatalf("failed to read synced manifest: %v", err)
+			}
+			if string(synced) != tc.expectedOut {
+				t.Errorf("expected synced manifest %q, got %q", tc.expectedOut, synced)
+			}
+		})
+	}
+}
+
+func TestProcessor_ProcessManifestContext_Cancelled(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 10.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			// Simulate an interrupt arriving while the output is half written.
+			os.WriteFile(outputFile, []byte("partial"), 0644)
+			cancel()
+			return context.Canceled
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithJobs(1))
+
+	manifestContent := "[0.0s–10.0s] (SPEAKER_00) " + filepath.Join(tmpDir, "a.wav") + "\n" +
+		"[10.0s–20.0s] (SPEAKER_00) " + filepath.Join(tmpDir, "b.wav") + "\n"
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	err := processor.ProcessManifestContext(ctx, manifestPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	for _, name := range []string{"a_synced.wav", "b_synced.wav", "manifest_synced.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed or never created", name)
+		}
+	}
+}
+
+func TestProcessor_ProcessManifestContext_CancelledAfterFinishedClips(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 10.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			if strings.HasSuffix(inputFile, "b.wav") {
+				// Simulate an interrupt arriving once the first clip is done.
+				cancel()
+				return context.Canceled
+			}
+			return os.WriteFile(outputFile, []byte("complete"), 0644)
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithJobs(1), WithOutput(io.Discard))
+
+	manifestContent := "[0.0s–10.0s] (SPEAKER_00) " + filepath.Join(tmpDir, "a.wav") + "\n" +
+		"[10.0s–20.0s] (SPEAKER_00) " + filepath.Join(tmpDir, "b.wav") + "\n"
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	report, err := processor.ProcessManifestReport(ctx, manifestPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	for _, name := range []string{"a_synced.wav", "b_synced.wav", "manifest_synced.txt"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); !os.IsNotExist(err) {
+			t.Errorf("expected %s to be removed or never created", name)
+		}
+	}
+	for _, e := range report.Entries {
+		if e.Status != StatusInterrupted || e.Output != "" {
+			t.Errorf("entry %d: expected an interrupted entry without an output, got %+v", e.Entry, e)
+		}
+	}
+	if report.Status != StatusInterrupted || report.Totals.Interrupted != 2 {
+		t.Errorf("expected an interrupted run with 2 interrupted entries, got %q and %+v", report.Status, report.Totals)
+	}
+}
+
+func TestProcessor_ProcessManifestContext_CancelledWhileProbingOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if strings.Contains(filePath, "_synced") {
+				// Simulate an interrupt arriving once the output is written.
+				cancel()
+				return 0, context.Canceled
+			}
+			return 10.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			return os.WriteFile(outputFile, []byte("complete"), 0644)
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithJobs(1))
+
+	manifestContent := "[0.0s–10.0s] (SPEAKER_00) " + filepath.Join(tmpDir, "a.wav") + "\n"
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte(manifestContent), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	err := processor.ProcessManifestContext(ctx, manifestPath)
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	files, err := os.ReadDir(tmpDir)
+	if err != nil {
+		t.Fatalf("failed to read temp dir: %v", err)
+	}
+	if len(files) != 1 || files[0].Name() != "manifest.txt" {
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Name())
+		}
+		t.Errorf("expected only the manifest to be left, got %v", names)
+	}
+}
+
+func TestGetSyncedManifestPath(t *testing.T) {
+	testCases := map[string]string{
+		"/work/manifest.txt": "/work/manifest_synced.txt",
+		"/work/episode.srt":  "/work/episode_synced.txt",
+		"/work/episode.rttm": "/work/episode_synced.txt",
+	}
+	for input, expected := range testCases {
+		if got := getSyncedManifestPath(input); got != expected {
+			t.Errorf("getSyncedManifestPath(%q): expected %q, got %q", input, expected, got)
+		}
+	}
+}
@@ -0,0 +1,169 @@
+package core
+
+import (
+	"context"
+	"time"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+// Statuses of a run and of its entries in a RunReport.
+const (
+	StatusOK     = "ok"
+	StatusFailed = "failed"
+	// StatusInterrupted marks an interrupted run, and the entries whose work
+	// the interruption cut off or undid.
+	StatusInterrupted = "interrupted"
+	// StatusSkipped marks entries a failed or interrupted run didn't reach.
+	StatusSkipped = "skipped"
+)
+
+// RunReport records what an adjust-speed or build run did, for tools that
+// would otherwise have to scrape its progress output. Durations and times
+// are in seconds unless their name says otherwise.
+type RunReport struct {
+	// Command is "adjust-speed" or "build".
+	Command  string `json:"command"`
+	Manifest string `json:"manifest"`
+	// Output is the synced manifest or the built audio file, if one was
+	// written.
+	Output string `json:"output,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+
+	Started  time.Time `json:"started"`
+	Finished time.Time `json:"finished"`
+	Seconds  float64   `json:"seconds"`
+	// RenderSeconds is how long build took to render the timeline.
+	RenderSeconds float64 `json:"render_seconds,omitempty"`
+
+	Totals  RunTotals     `json:"totals"`
+	Entries []EntryReport `json:"entries"`
+}
+
+// RunTotals sums up the entries of a RunReport.
+type RunTotals struct {
+	Entries     int `json:"entries"`
+	Succeeded   int `json:"succeeded"`
+	Failed      int `json:"failed"`
+	Interrupted int `json:"interrupted"`
+	Skipped     int `json:"skipped"`
+	// Clamped, OverrunMs and UnderrunMs are set by adjust-speed; see
+	// SpeedPlan.
+	Clamped    int     `json:"clamped,omitempty"`
+	OverrunMs  float64 `json:"overrun_ms,omitempty"`
+	UnderrunMs float64 `json:"underrun_ms,omitempty"`
+	// WorstLateMs, Overflows and OutputDuration are set by build: how late
+	// the most displaced clip starts, how many clips the overflow policy
+	// acted on, and the length of the built file.
+	WorstLateMs    float64 `json:"worst_late_ms,omitempty"`
+	Overflows      int     `json:"overflows,omitempty"`
+	OutputDuration float64 `json:"output_duration,omitempty"`
+}
+
+// EntryReport records what a run did with one manifest entry.
+type EntryReport struct {
+	// Entry is the one-based position of the entry in the manifest.
+	Entry  int    `json:"entry"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	Input  string `json:"input"`
+	// Output is the synced clip written by adjust-speed.
+	Output           string  `json:"output,omitempty"`
+	Start            float64 `json:"start"`
+	ManifestDuration float64 `json:"manifest_duration"`
+	ActualDuration   float64 `json:"actual_duration,omitempty"`
+	// Speed is the factor that would fit the clip exactly, and AppliedSpeed
+	// the one adjust-speed achieved, measured from the clip it wrote.
+	Speed        float64 `json:"speed,omitempty"`
+	AppliedSpeed float64 `json:"applied_speed,omitempty"`
+	// ResidualMs is the error left after the run, in milliseconds: for
+	// adjust-speed, how much longer the adjusted clip, as written, is than
+	// its slot; for build, how late the clip starts.
+	ResidualMs float64 `json:"residual_ms"`
+	// RealizedStart is where build placed the clip.
+	RealizedStart *float64 `json:"realized_start,omitempty"`
+	// Overflow says what build's overflow policy did to the clip: "pushed"
+	// (started late), "overlapped" (started while an earlier clip played)
+	// or "truncated" (cut short), by OverflowMs milliseconds.
+	Overflow   string  `json:"overflow,omitempty"`
+	OverflowMs float64 `json:"overflow_ms,omitempty"`
+	// Seconds is how long adjust-speed spent on the entry.
+	Seconds float64 `json:"seconds,omitempty"`
+}
+
+func newRunReport(command, manifestPath string) *RunReport {
+	return &RunReport{Command: command, Manifest: manifestPath, Started: time.Now(), Entries: []EntryReport{}}
+}
+
+// setEntries lists entries in the report, as skipped until they are reached.
+func (r *RunReport) setEntries(entries []manifest.ManifestEntry) {
+	r.Entries = make([]EntryReport, len(entries))
+	for i, entry := range entries {
+		r.Entries[i] = EntryReport{
+			Entry:            i + 1,
+			Status:           StatusSkipped,
+			Input:            entry.FilePath,
+			Start:            entry.StartTime,
+			ManifestDuration: entry.EndTime - entry.StartTime,
+		}
+	}
+}
+
+// finish records the outcome of the run and totals its entries. The run was
+// interrupted if ctx is done.
+func (r *RunReport) finish(ctx context.Context, err error) {
+	r.Finished = time.Now()
+	r.Seconds = r.Finished.Sub(r.Started).Seconds()
+	switch {
+	case err == nil:
+		r.Status = StatusOK
+	case ctx.Err() != nil:
+		r.Status = StatusInterrupted
+	default:
+		r.Status = StatusFailed
+	}
+	if err != nil {
+		r.Error = err.Error()
+	}
+
+	r.Totals.Entries = len(r.Entries)
+	for _, e := range r.Entries {
+		switch e.Status {
+		case StatusOK:
+			r.Totals.Succeeded++
+		case StatusFailed:
+			r.Totals.Failed++
+		case StatusInterrupted:
+			r.Totals.Interrupted++
+		default:
+			r.Totals.Skipped++
+		}
+	}
+}
+
+// fail records err as the reason e didn't succeed. The entry was
+// interrupted if ctx is done.
+func (e *EntryReport) fail(ctx context.Context, err error) {
+	e.Status = StatusFailed
+	if ctx.Err() != nil {
+		e.Status = StatusInterrupted
+	}
+	e.Error = err.Error()
+}
+
+// recordSpeedChange records in e how adjusting the speed of its entry went.
+// outputDuration is the duration of the adjusted clip, as probed, which
+// processEntry only returns without an error if it is positive.
+func recordSpeedChange(ctx context.Context, e *EntryReport, change SpeedChange, output string, outputDuration float64, err error) {
+	e.ActualDuration = change.ActualDuration
+	e.Speed = change.Speed
+	if err != nil {
+		e.fail(ctx, err)
+		return
+	}
+	e.Status = StatusOK
+	e.Output = output
+	e.AppliedSpeed = change.ActualDuration / outputDuration
+	e.ResidualMs = (outputDuration - change.ManifestDuration) * 1000
+}
@@ -0,0 +1,256 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+)
+
+func TestProcessor_ProcessManifestReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–1.0s] (A) a.wav\n[1.0s–2.0s] (B) b.wav\n[2.0s–3.0s] (A) c.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	// The backend pads every clip it writes by 125ms, which the report
+	// should measure rather than take from the plan.
+	var mu sync.Mutex
+	written := make(map[string]float64)
+	duration := func(filePath string) float64 {
+		if strings.HasSuffix(filePath, "b.wav") {
+			return 2.5
+		}
+		return 1.0
+	}
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if d, ok := written[filePath]; ok {
+				return d, nil
+			}
+			return duration(filePath), nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			if strings.HasSuffix(inputFile, "c.wav") {
+				return fmt.Errorf("ffmpeg failed")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			written[outputFile] = duration(inputFile)/speed + 0.125
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+	report, err := processor.ProcessManifestReport(context.Background(), manifestPath)
+	if err != nil {
+		t.Fatalf("ProcessManifestReport() error = %v", err)
+	}
+	if report.Command != "adjust-speed" || report.Status != StatusOK {
+		t.Errorf("expected an ok adjust-speed report, got %q and %q", report.Command, report.Status)
+	}
+	if report.Output != filepath.Join(tmpDir, "manifest_synced.txt") {
+		t.Errorf("expected the synced manifest as output, got %q", report.Output)
+	}
+	if report.Finished.Before(report.Started) || report.Seconds < 0 {
+		t.Errorf("expected a valid timing, got %v to %v", report.Started, report.Finished)
+	}
+
+	expectedTotals := RunTotals{Entries: 3, Succeeded: 2, Failed: 1, Clamped: 1, OverrunMs: 1250}
+	if report.Totals != expectedTotals {
+		t.Errorf("expected totals %+v, got %+v", expectedTotals, report.Totals)
+	}
+
+	clamped := report.Entries[1]
+	if clamped.Status != StatusOK || clamped.Output != filepath.Join(tmpDir, "b_synced.wav") ||
+		clamped.Speed != 2.5 || clamped.AppliedSpeed != 2.5/2.125 || clamped.ResidualMs != 1125 {
+		t.Errorf("unexpected report for the clamped entry: %+v", clamped)
+	}
+	failed := report.Entries[2]
+	if failed.Status != StatusFailed || failed.Output != "" || !strings.Contains(failed.Error, "ffmpeg failed") {
+		t.Errorf("unexpected report for the failed entry: %+v", failed)
+	}
+}
+
+func TestProcessor_ProcessManifestReport_EmptyOutput(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–1.0s] (A) a.wav\n[1.0s–2.0s] (B) b.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	// The backend writes b's clip empty, so no speed can be measured from it.
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if strings.HasSuffix(filePath, "b_synced.wav") {
+				return 0, nil
+			}
+			return 1.0, nil
+		},
+		ApplySpeedFunc: func(inputFile, outputFile string, speed float64) error {
+			return os.WriteFile(outputFile, nil, 0644)
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+	report, err := processor.ProcessManifestReport(context.Background(), manifestPath)
+	if err != nil {
+		t.Fatalf("ProcessManifestReport() error = %v", err)
+	}
+	empty := report.Entries[1]
+	if empty.Status != StatusFailed || empty.Output != "" || !strings.Contains(empty.Error, "has no duration") {
+		t.Errorf("unexpected report for the empty clip: %+v", empty)
+	}
+	if _, err := os.Stat(filepath.Join(tmpDir, "b_synced.wav")); !os.IsNotExist(err) {
+		t.Errorf("expected the empty clip to be removed")
+	}
+	if _, err := json.Marshal(report); err != nil {
+		t.Errorf("failed to marshal report: %v", err)
+	}
+
+}
+
+func TestProcessor_ProcessManifestReport_InvalidManifest(t *testing.T) {
+	processor := NewProcessor(&MockAudioProcessor{}, WithOutput(io.Discard))
+	report, err := processor.ProcessManifestReport(context.Background(), filepath.Join(t.TempDir(), "none.txt"))
+	if err == nil {
+		t.Fatal("expected an error for a missing manifest")
+	}
+	if report.Status != StatusFailed || report.Error == "" || len(report.Entries) != 0 {
+		t.Errorf("expected a failed report with the error, got %+v", report)
+	}
+}
+
+func TestProcessor_BuildFromManifestReport(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–1.0s] (A) /fake/a.wav\n[1.0s–2.0s] (B) /fake/b.wav\n[2.5s–3.0s] (A) /fake/c.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if filePath == "/fake/a.wav" {
+				return 1.25, nil
+			}
+			return 1.0, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			return nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+	outputPath := filepath.Join(tmpDir, "out.wav")
+	report, err := processor.BuildFromManifestReport(context.Background(), manifestPath, outputPath)
+	if err != nil {
+		t.Fatalf("BuildFromManifestReport() error = %v", err)
+	}
+	if report.Command != "build" || report.Status != StatusOK || report.Output != outputPath {
+		t.Errorf("expected an ok build report for %s, got %+v", outputPath, report)
+	}
+
+	expectedStarts := []float64{0, 1.25, 2.5}
+	expectedResiduals := []float64{0, 250, 0}
+	for i, e := range report.Entries {
+		if e.Status != StatusOK || e.RealizedStart == nil {
+			t.Fatalf("entry %d: expected a placed entry, got %+v", i+1, e)
+		}
+		if *e.RealizedStart != expectedStarts[i] || e.ResidualMs != expectedResiduals[i] {
+			t.Errorf("entry %d: expected start %.3fs and residual %.0fms, got %.3fs and %.0fms",
+				i+1, expectedStarts[i], expectedResiduals[i], *e.RealizedStart, e.ResidualMs)
+		}
+	}
+	expectedTotals := RunTotals{Entries: 3, Succeeded: 3, WorstLateMs: 250, Overflows: 1, OutputDuration: 3.5}
+	if report.Totals != expectedTotals {
+		t.Errorf("expected totals %+v, got %+v", expectedTotals, report.Totals)
+	}
+}
+
+func TestProcessor_BuildFromManifestReport_ProbeFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–1.0s] (A) /fake/a.wav\n[1.0s–2.0s] (B) /fake/b.wav\n[2.0s–3.0s] (A) /fake/c.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if filePath == "/fake/b.wav" {
+				return 0, fmt.Errorf("no such file")
+			}
+			return 1.0, nil
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+	report, err := processor.BuildFromManifestReport(context.Background(), manifestPath, filepath.Join(tmpDir, "out.wav"))
+	if err == nil {
+		t.Fatal("expected an error for an unreadable clip")
+	}
+	statuses := []string{report.Entries[0].Status, report.Entries[1].Status, report.Entries[2].Status}
+	// Nothing was rendered, so the entry placed before the failure wasn't
+	// built either.
+	expected := []string{StatusSkipped, StatusFailed, StatusSkipped}
+	for i := range expected {
+		if statuses[i] != expected[i] {
+			t.Errorf("expected statuses %v, got %v", expected, statuses)
+			break
+		}
+	}
+	if report.Status != StatusFailed || report.Output != "" {
+		t.Errorf("expected a failed report without output, got %+v", report)
+	}
+	if report.Totals.Succeeded != 0 || report.Totals.Failed != 1 || report.Totals.Skipped != 2 {
+		t.Errorf("unexpected totals %+v", report.Totals)
+	}
+}
+
+func TestProcessor_BuildFromManifestReport_RenderFailure(t *testing.T) {
+	tmpDir := t.TempDir()
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "[0.0s–1.0s] (A) /fake/a.wav\n[1.0s–2.0s] (B) /fake/b.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			return 1.0, nil
+		},
+		RenderFunc: func(tl audio.Timeline, outputFile string) error {
+			return fmt.Errorf("disk full")
+		},
+	}
+	processor := NewProcessor(mockAudioProc, WithOutput(io.Discard))
+
+	report, err := processor.BuildFromManifestReport(context.Background(), manifestPath, filepath.Join(tmpDir, "out.wav"))
+	if err == nil {
+		t.Fatal("expected a render error")
+	}
+	for _, e := range report.Entries {
+		if e.Status != StatusFailed || !strings.Contains(e.Error, "disk full") {
+			t.Errorf("entry %d: expected a failed entry with the render error, got %q (%s)", e.Entry, e.Status, e.Error)
+		}
+	}
+	if report.Status != StatusFailed || report.Output != "" {
+		t.Errorf("expected a failed report without output, got %+v", report)
+	}
+	if report.Totals.Succeeded != 0 || report.Totals.Failed != 2 {
+		t.Errorf("unexpected totals %+v", report.Totals)
+	}
+}
@@ -0,0 +1,82 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+// settings are the processing parameters that a manifest can override with
+// header directives such as "#! min_speed=0.85".
+type settings struct {
+	minSpeed float64
+	maxSpeed float64
+	// sampleRate is the output rate of a build; zero keeps the rate of the
+	// first clip.
+	sampleRate int
+	// bed is an audio file mixed under the whole build, such as music or room
+	// tone, at bedGain decibels.
+	bed     string
+	bedGain float64
+}
+
+func defaultSettings() settings {
+	return settings{minSpeed: minSpeed, maxSpeed: maxSpeed}
+}
+
+// manifestSettings applies the directives of m to the default settings.
+// Unknown directives are reported and ignored.
+func (p *Processor) manifestSettings(m *manifest.Manifest) (settings, error) {
+	s, unknown, err := parseSettings(m)
+	for _, key := range unknown {
+		fmt.Fprintf(p.out, "Warning: ignoring unknown directive %q\n", key)
+	}
+	return s, err
+}
+
+// parseSettings applies the directives of m to the default settings, and
+// returns the names of directives it doesn't know. A relative bed path is
+// resolved like clip paths.
+func parseSettings(m *manifest.Manifest) (settings, []string, error) {
+	s := defaultSettings()
+	var unknown []string
+	for _, d := range m.Directives {
+		var err error
+		switch d.Key {
+		case "min_speed":
+			s.minSpeed, err = parsePositive(d.Value)
+		case "max_speed":
+			s.maxSpeed, err = parsePositive(d.Value)
+		case "sample_rate":
+			s.sampleRate, err = strconv.Atoi(d.Value)
+			if err == nil && s.sampleRate <= 0 {
+				err = fmt.Errorf("must be positive")
+			}
+		case "bed":
+			s.bed = m.ResolvePath(d.Value)
+		case "bed_gain":
+			s.bedGain, err = strconv.ParseFloat(d.Value, 64)
+		default:
+			unknown = append(unknown, d.Key)
+		}
+		if err != nil {
+			return settings{}, unknown, fmt.Errorf("%w: directive %s=%q: %w", ErrInvalidManifest, d.Key, d.Value, err)
+		}
+	}
+	if s.minSpeed > s.maxSpeed {
+		return settings{}, unknown, fmt.Errorf("%w: min_speed %g is above max_speed %g", ErrInvalidManifest, s.minSpeed, s.maxSpeed)
+	}
+	return s, unknown, nil
+}
+
+func parsePositive(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, err
+	}
+	if v <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return v, nil
+}
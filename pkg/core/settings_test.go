@@ -0,0 +1,58 @@
+package core
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+func TestManifestSettings(t *testing.T) {
+	var out bytes.Buffer
+	p := NewProcessor(&MockAudioProcessor{})
+	p.out = &out
+
+	m := &manifest.Manifest{Directives: []manifest.Directive{
+		{Key: "sample_rate", Value: "48000"},
+		{Key: "min_speed", Value: "0.85"},
+		{Key: "bed", Value: "music.wav"},
+		{Key: "bed_gain", Value: "-12"},
+		{Key: "director", Value: "Ana"},
+	}}
+	s, err := p.manifestSettings(m)
+	if err != nil {
+		t.Fatalf("manifestSettings() error = %v", err)
+	}
+	expected := settings{minSpeed: 0.85, maxSpeed: maxSpeed, sampleRate: 48000, bed: "music.wav", bedGain: -12}
+	if s != expected {
+		t.Errorf("expected %+v, got %+v", expected, s)
+	}
+	if !strings.Contains(out.String(), `unknown directive "director"`) {
+		t.Errorf("expected a warning about the unknown directive, got %q", out.String())
+	}
+}
+
+func TestManifestSettings_Invalid(t *testing.T) {
+	testCases := []struct {
+		name       string
+		directives []manifest.Directive
+	}{
+		{"non-numeric speed", []manifest.Directive{{Key: "min_speed", Value: "fast"}}},
+		{"zero speed", []manifest.Directive{{Key: "max_speed", Value: "0"}}},
+		{"min above max", []manifest.Directive{{Key: "min_speed", Value: "1.5"}}},
+		{"fractional sample rate", []manifest.Directive{{Key: "sample_rate", Value: "44.1"}}},
+		{"negative sample rate", []manifest.Directive{{Key: "sample_rate", Value: "-48000"}}},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			p := NewProcessor(&MockAudioProcessor{})
+			_, err := p.manifestSettings(&manifest.Manifest{Directives: tc.directives})
+			if !errors.Is(err, ErrInvalidManifest) {
+				t.Errorf("expected ErrInvalidManifest, got %v", err)
+			}
+		})
+	}
+}
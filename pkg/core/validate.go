@@ -0,0 +1,225 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/manifest"
+)
+
+// Severity says how serious a validation issue is. Errors make the manifest
+// fail validation; warnings don't, unless validating strictly.
+type Severity string
+
+const (
+	// SeverityError marks a problem that would break or desync a run.
+	SeverityError Severity = "error"
+	// SeverityWarning marks something that is likely, but not surely, wrong.
+	SeverityWarning Severity = "warning"
+)
+
+// Issue is a problem found in a manifest by Validate.
+type Issue struct {
+	Severity Severity `json:"severity"`
+	// Check names the check that found the issue: "syntax", "directive",
+	// "duration", "order", "overlap", "duplicate-path", "missing-clip",
+	// "unreadable-clip", "sample-rate" or "speed".
+	Check string `json:"check"`
+	// Entry is the one-based position of the entry the issue is about, or
+	// zero for issues with the manifest as a whole.
+	Entry int    `json:"entry,omitempty"`
+	Path  string `json:"path,omitempty"`
+	// Line and Column locate syntax errors in the manifest file.
+	Line    int    `json:"line,omitempty"`
+	Column  int    `json:"column,omitempty"`
+	Message string `json:"message"`
+}
+
+// ValidationReport lists the issues found in a manifest.
+type ValidationReport struct {
+	Manifest string  `json:"manifest"`
+	Entries  int     `json:"entries"`
+	Errors   int     `json:"errors"`
+	Warnings int     `json:"warnings"`
+	Issues   []Issue `json:"issues"`
+}
+
+func (r *ValidationReport) add(issue Issue) {
+	if issue.Severity == SeverityError {
+		r.Errors++
+	} else {
+		r.Warnings++
+	}
+	r.Issues = append(r.Issues, issue)
+}
+
+// WriteText writes the report for people, one issue per line. Syntax errors
+// are located as file:line:col.
+func (r *ValidationReport) WriteText(w io.Writer) {
+	for _, issue := range r.Issues {
+		switch {
+		case issue.Line > 0:
+			fmt.Fprintf(w, "%s:%d:%d: %s: %s\n", r.Manifest, issue.Line, issue.Column, issue.Severity, issue.Message)
+		case issue.Entry > 0:
+			fmt.Fprintf(w, "%s: entry %d (%s): %s: %s\n", r.Manifest, issue.Entry, issue.Path, issue.Severity, issue.Message)
+		default:
+			fmt.Fprintf(w, "%s: %s: %s\n", r.Manifest, issue.Severity, issue.Message)
+		}
+	}
+	fmt.Fprintf(w, "%d entries, %d errors, %d warnings\n", r.Entries, r.Errors, r.Warnings)
+}
+
+// Validate checks a manifest for problems that would spoil a run, without
+// processing any audio: entries that are out of order, overlap or don't
+// last, clips that are missing, unreadable or used twice, clips at a sample
+// rate the backend can't convert, and clips whose speed factor falls outside
+// the clamp range. Those speed factors are errors unless an overflow policy
+// has been set with WithOverflow, though clips left too long stay errors
+// under OverflowFail. Clip durations are read with the audio backend. An
+// error is returned only if the manifest can't be read at all, or ctx is
+// done.
+func (p *Processor) Validate(ctx context.Context, manifestPath string) (*ValidationReport, error) {
+	report := &ValidationReport{Manifest: manifestPath, Issues: []Issue{}}
+
+	opts := p.manifest
+	opts.CollectErrors = true
+	m, err := manifest.Load(manifestPath, opts)
+	if err != nil {
+		var pe *manifest.ParseError
+		if !errors.As(err, &pe) {
+			return nil, fmt.Errorf("%w: %w", ErrInvalidManifest, err)
+		}
+		var list manifest.ErrorList
+		if !errors.As(err, &list) {
+			list = manifest.ErrorList{pe}
+		}
+		for _, e := range list {
+			report.add(Issue{Severity: SeverityError, Check: "syntax", Line: e.Line, Column: e.Column, Message: e.Reason})
+		}
+		return report, nil
+	}
+	report.Entries = len(m.Entries)
+
+	s, unknown, err := parseSettings(m)
+	if err != nil {
+		report.add(Issue{Severity: SeverityError, Check: "directive", Message: err.Error()})
+		s = defaultSettings()
+	}
+	for _, key := range unknown {
+		report.add(Issue{Severity: SeverityWarning, Check: "directive", Message: fmt.Sprintf("unknown directive %q", key)})
+	}
+	if s.bed != "" {
+		if _, err := os.Stat(s.bed); err != nil {
+			report.add(Issue{Severity: SeverityError, Check: "missing-clip", Path: s.bed, Message: fmt.Sprintf("cannot read bed: %v", err)})
+		}
+	}
+
+	// A backend that can't resample needs every clip at the output rate,
+	// which is that of the first clip unless the manifest sets one.
+	renderer, _ := p.audioProc.(audio.Renderer)
+	checkRate := renderer != nil && !resamples(renderer)
+	outputRate := s.sampleRate
+	if checkRate && outputRate == 0 && len(m.Entries) > 0 {
+		// Work the rate out as the build does. If the first clip can't be
+		// read, the build stops there, which is reported below, so the
+		// rates of the others can't be checked.
+		if format, err := p.outputFormat(ctx, renderer, s, m.Entries); err == nil {
+			outputRate = format.SampleRate
+		} else if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, ctxErr
+		}
+	}
+
+	firstUse := make(map[string]int)
+	latestEnd := -1
+	for i, entry := range m.Entries {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		n := i + 1
+		issue := func(severity Severity, check, format string, args ...any) {
+			report.add(Issue{Severity: severity, Check: check, Entry: n, Path: entry.FilePath, Message: fmt.Sprintf(format, args...)})
+		}
+
+		duration := entry.EndTime - entry.StartTime
+		if duration <= 0 {
+			issue(SeverityError, "duration", "ends at %.3fs, not after its start at %.3fs", entry.EndTime, entry.StartTime)
+		}
+		if i > 0 {
+			prev := m.Entries[i-1]
+			if entry.StartTime < prev.StartTime {
+				issue(SeverityError, "order", "starts at %.3fs, before entry %d at %.3fs", entry.StartTime, i, prev.StartTime)
+			} else if latest := m.Entries[latestEnd]; entry.StartTime < latest.EndTime {
+				issue(SeverityError, "overlap", "starts %.3fs before entry %d ends", latest.EndTime-entry.StartTime, latestEnd+1)
+			}
+		}
+		if latestEnd < 0 || entry.EndTime > m.Entries[latestEnd].EndTime {
+			latestEnd = i
+		}
+		if first, ok := firstUse[entry.FilePath]; ok {
+			// adjust-speed gives each use its own output, but the clip is
+			// likely listed twice by mistake.
+			issue(SeverityWarning, "duplicate-path", "uses the same clip as entry %d", first)
+		} else {
+			firstUse[entry.FilePath] = n
+		}
+
+		info, err := os.Stat(entry.FilePath)
+		switch {
+		case errors.Is(err, os.ErrNotExist):
+			issue(SeverityError, "missing-clip", "clip not found")
+			continue
+		case err != nil:
+			issue(SeverityError, "unreadable-clip", "%v", err)
+			continue
+		case info.IsDir():
+			issue(SeverityError, "unreadable-clip", "clip is a directory")
+			continue
+		}
+		actual, err := p.getDuration(ctx, entry.FilePath)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return nil, ctxErr
+			}
+			issue(SeverityError, "unreadable-clip", "cannot read duration: %v", err)
+			continue
+		}
+		if checkRate {
+			format, err := p.getFormat(ctx, renderer, entry.FilePath)
+			switch {
+			case err != nil:
+				if ctxErr := ctx.Err(); ctxErr != nil {
+					return nil, ctxErr
+				}
+				issue(SeverityError, "unreadable-clip", "cannot read format: %v", err)
+			case outputRate > 0 && format.SampleRate != outputRate:
+				issue(SeverityError, "sample-rate", "is %d Hz, but the output is %d Hz and the backend cannot resample", format.SampleRate, outputRate)
+			}
+		}
+		if duration <= 0 || actual <= 0 {
+			continue
+		}
+		speed := actual / duration
+		if clamped := clamp(speed, s.minSpeed, s.maxSpeed); clamped != speed {
+			// The clip will still be too long or too short by this much.
+			off := actual/clamped - duration
+			when := "late"
+			if off < 0 {
+				off, when = -off, "early"
+			}
+			// Clips that end early leave silence, and an overflow policy
+			// decides what happens to ones that end late.
+			severity := SeverityError
+			if p.overflow != "" && (p.overflow != OverflowFail || when == "early") {
+				severity = SeverityWarning
+			}
+			issue(severity, "speed", "needs speed %.3f to fit, outside %g–%g; it will end %.3fs %s", speed, s.minSpeed, s.maxSpeed, off, when)
+		}
+	}
+
+	return report, nil
+}
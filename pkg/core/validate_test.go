@@ -0,0 +1,246 @@
+package core
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/viniciusrtf/sync-audio-with-timestamps/internal/audio"
+)
+
+func TestProcessor_Validate(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.wav", "b.wav", "c.wav", "d.wav", "long.wav"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to create temp clip: %v", err)
+		}
+	}
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	content := "#! tempo=fast\n" +
+		"[0.0s–2.0s] (A) a.wav\n" +
+		"[1.5s–3.0s] (B) b.wav\n" +
+		"[1.0s–2.0s] (A) c.wav\n" +
+		"[4.0s–4.0s] (B) d.wav\n" +
+		"[5.0s–7.0s] (A) a.wav\n" +
+		"[8.0s–9.0s] (B) missing.wav\n" +
+		"[9.0s–10.0s] (A) long.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			switch filepath.Base(filePath) {
+			case "b.wav":
+				return 1.5, nil
+			case "c.wav", "d.wav":
+				return 1.0, nil
+			}
+			return 2.0, nil
+		},
+	}
+	report, err := NewProcessor(mockAudioProc).Validate(context.Background(), manifestPath)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+
+	type found struct {
+		severity Severity
+		check    string
+		entry    int
+	}
+	var got []found
+	for _, issue := range report.Issues {
+		got = append(got, found{issue.Severity, issue.Check, issue.Entry})
+	}
+	expected := []found{
+		{SeverityWarning, "directive", 0},
+		{SeverityError, "overlap", 2},
+		{SeverityError, "order", 3},
+		{SeverityError, "duration", 4},
+		{SeverityWarning, "duplicate-path", 5},
+		{SeverityError, "missing-clip", 6},
+		{SeverityError, "speed", 7},
+	}
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("expected issues %+v, got %+v", expected, got)
+	}
+	if report.Entries != 7 || report.Errors != 5 || report.Warnings != 2 {
+		t.Errorf("expected 7 entries, 5 errors and 2 warnings, got %d, %d and %d", report.Entries, report.Errors, report.Warnings)
+	}
+	if msg := report.Issues[6].Message; !strings.Contains(msg, "end 0.600s late") {
+		t.Errorf("expected the speed error to say how late the clip ends, got %q", msg)
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.HasSuffix(out.String(), "7 entries, 5 errors, 2 warnings\n") {
+		t.Errorf("expected a summary line, got %q", out.String())
+	}
+
+	data, err := json.Marshal(report)
+	if err != nil {
+		t.Fatalf("failed to marshal report: %v", err)
+	}
+	var decoded ValidationReport
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("failed to unmarshal report: %v", err)
+	}
+	if !reflect.DeepEqual(decoded, *report) {
+		t.Errorf("expected the JSON report to round-trip, got %+v", decoded)
+	}
+}
+
+func TestProcessor_Validate_SpeedWithOverflow(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"long.wav", "short.wav"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to create temp clip: %v", err)
+		}
+	}
+	manifestPath := filepath.Join(tmpDir, "manifest.txt")
+	if err := os.WriteFile(manifestPath, []byte("[0.0s–1.0s] (A) long.wav\n[2.0s–3.0s] (B) short.wav\n"), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+	mockAudioProc := &MockAudioProcessor{
+		GetDurationFunc: func(filePath string) (float64, error) {
+			if filepath.Base(filePath) == "long.wav" {
+				return 2.0, nil
+			}
+			return 0.5, nil
+		},
+	}
+
+	testCases := []struct {
+		name string
+		opts []Option
+		// expected holds the severities of the long and the short clip.
+		expected []Severity
+	}{
+		{"no policy", nil, []Severity{SeverityError, SeverityError}},
+		{"push", []Option{WithOverflow(OverflowPush)}, []Severity{SeverityWarning, SeverityWarning}},
+		{"fail", []Option{WithOverflow(OverflowFail)}, []Severity{SeverityError, SeverityWarning}},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			report, err := NewProcessor(mockAudioProc, tc.opts...).Validate(context.Background(), manifestPath)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			var got []Severity
+			for _, issue := range report.Issues {
+				if issue.Check == "speed" {
+					got = append(got, issue.Severity)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected speed issues %v, got %v", tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestProcessor_Validate_SampleRate(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"a.wav", "b.wav"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), nil, 0644); err != nil {
+			t.Fatalf("failed to create temp clip: %v", err)
+		}
+	}
+	rates := map[string]int{"a.wav": 1000, "b.wav": 2000}
+
+	testCases := []struct {
+		name      string
+		directive string
+		// first is the clip of the first entry, before a.wav and b.wav.
+		first     string
+		resamples bool
+		// expected lists the entries with a sample-rate error.
+		expected []int
+	}{
+		{"first clip's rate", "", "", false, []int{2}},
+		{"manifest rate", "#! sample_rate=2000\n", "", false, []int{1}},
+		{"resampling backend", "#! sample_rate=2000\n", "", true, nil},
+		// The build stops at a missing first clip, so the rate of the next
+		// one means nothing.
+		{"missing first clip", "", "missing.wav", false, nil},
+	}
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			manifestPath := filepath.Join(tmpDir, "manifest.txt")
+			content := tc.directive + "[0.0s–1.0s] (A) a.wav\n[1.0s–2.0s] (B) b.wav\n"
+			if tc.first != "" {
+				content = tc.directive + "[0.0s–1.0s] (A) " + tc.first + "\n[1.0s–2.0s] (A) a.wav\n[2.0s–3.0s] (B) b.wav\n"
+			}
+			if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+				t.Fatalf("failed to create temp manifest file: %v", err)
+			}
+			mockAudioProc := &MockAudioProcessor{
+				GetDurationFunc: func(filePath string) (float64, error) {
+					return 1.0, nil
+				},
+				GetFormatFunc: func(filePath string) (audio.Format, error) {
+					rate, ok := rates[filepath.Base(filePath)]
+					if !ok {
+						return audio.Format{}, os.ErrNotExist
+					}
+					return audio.Format{SampleRate: rate, Channels: 1, BitsPerSample: 16}, nil
+				},
+				CannotResample: !tc.resamples,
+			}
+
+			report, err := NewProcessor(mockAudioProc).Validate(context.Background(), manifestPath)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			var got []int
+			for _, issue := range report.Issues {
+				if issue.Check == "sample-rate" && issue.Severity == SeverityError {
+					got = append(got, issue.Entry)
+				}
+			}
+			if !reflect.DeepEqual(got, tc.expected) {
+				t.Errorf("expected sample-rate errors for entries %v, got %v (%+v)", tc.expected, got, report.Issues)
+			}
+		})
+	}
+}
+
+func TestProcessor_Validate_SyntaxErrors(t *testing.T) {
+	manifestPath := filepath.Join(t.TempDir(), "manifest.txt")
+	content := "[0.0s–1.0s] (A) a.wav\n[1.0s–2.0s (A) b.wav\n[x] (A) c.wav\n"
+	if err := os.WriteFile(manifestPath, []byte(content), 0644); err != nil {
+		t.Fatalf("failed to create temp manifest file: %v", err)
+	}
+
+	report, err := NewProcessor(&MockAudioProcessor{}).Validate(context.Background(), manifestPath)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	expected := []Issue{
+		{Severity: SeverityError, Check: "syntax", Line: 2, Column: 12, Message: `expected "]"`},
+		{Severity: SeverityError, Check: "syntax", Line: 3, Column: 2, Message: `invalid time "x" (expected e.g. 12.5s, 12500ms or 00:00:12.500)`},
+	}
+	if !reflect.DeepEqual(report.Issues, expected) {
+		t.Errorf("expected %+v, got %+v", expected, report.Issues)
+	}
+
+	var out bytes.Buffer
+	report.WriteText(&out)
+	if !strings.HasPrefix(out.String(), manifestPath+`:2:12: error: expected "]"`) {
+		t.Errorf("expected issues located as file:line:col, got %q", out.String())
+	}
+}
+
+func TestProcessor_Validate_MissingManifest(t *testing.T) {
+	_, err := NewProcessor(&MockAudioProcessor{}).Validate(context.Background(), filepath.Join(t.TempDir(), "none.txt"))
+	if !errors.Is(err, ErrInvalidManifest) {
+		t.Errorf("expected ErrInvalidManifest, got %v", err)
+	}
+}